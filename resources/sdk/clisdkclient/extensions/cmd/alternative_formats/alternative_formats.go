@@ -8,9 +8,13 @@ import (
 	"strings"
 )
 
-var validFormats = [...]string{"JSON", "YAML"}
+var (
+	validInputFormats = []string{"JSON", "YAML", "CSV"}
+	// CSV, TSV and TABLE flatten the output, so can't be read back in as input
+	validOutputFormats = []string{"JSON", "YAML", "CSV", "TSV", "TABLE"}
+)
 
-func isValidDataFormat(formatName string) bool {
+func isValidDataFormat(formatName string, validFormats []string) bool {
 	for _, v := range validFormats {
 		if strings.EqualFold(v, formatName) {
 			return true
@@ -73,7 +77,7 @@ var setOutputFormatCmd = &cobra.Command{
 	Args:  cobra.ExactArgs(1),
 
 	Run: func(cmd *cobra.Command, args []string) {
-		if isValidDataFormat(args[0]) {
+		if isValidDataFormat(args[0], validOutputFormats) {
 			profileName, _ := cmd.Root().Flags().GetString("profile")
 			err := config.SetOutputFormat(profileName, args[0])
 			if err != nil {
@@ -90,7 +94,7 @@ var setInputFormatCmd = &cobra.Command{
 	Args:  cobra.ExactArgs(1),
 
 	Run: func(cmd *cobra.Command, args []string) {
-		if isValidDataFormat(args[0]) {
+		if isValidDataFormat(args[0], validInputFormats) {
 			profileName, _ := cmd.Root().Flags().GetString("profile")
 			err := config.SetInputFormat(profileName, args[0])
 			if err != nil {
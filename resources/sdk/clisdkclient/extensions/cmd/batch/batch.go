@@ -0,0 +1,77 @@
+package batch
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mypurecloud/platform-client-sdk-cli/build/gc/logger"
+	"github.com/mypurecloud/platform-client-sdk-cli/build/gc/models"
+	"github.com/mypurecloud/platform-client-sdk-cli/build/gc/services"
+	"github.com/mypurecloud/platform-client-sdk-cli/build/gc/utils"
+	"github.com/spf13/cobra"
+)
+
+var batchCmd = &cobra.Command{
+	Use:   "batch",
+	Short: "Runs the operations listed in a manifest file",
+	Long: `Runs the operations listed in a YAML or JSON manifest file in one process, sharing a client and access token per profile.
+Each item names either a command (e.g. "users get") or a method and path, along with its args, query, body or body file and profile.
+A report of each item's status code, correlation ID and response or error is printed once all the items have run`,
+	Args: cobra.NoArgs,
+
+	Run: func(cmd *cobra.Command, args []string) {
+		logger.InitLogger(cmd)
+
+		fileName, _ := cmd.Flags().GetString("file")
+		manifest, err := services.LoadBatchManifest(fileName)
+		if err != nil {
+			logger.Fatal(err)
+		}
+		if cmd.Flags().Changed("concurrency") {
+			manifest.Concurrency, _ = cmd.Flags().GetInt("concurrency")
+		}
+		if cmd.Flags().Changed("continue-on-error") {
+			manifest.ContinueOnError, _ = cmd.Flags().GetBool("continue-on-error")
+		}
+		if manifest.Concurrency < 0 {
+			logger.Fatal("--concurrency must not be negative")
+		}
+
+		profileName, _ := cmd.Root().Flags().GetString("profile")
+		report := services.RunBatch(manifest, profileName, resolveCommand(cmd.Root()))
+
+		reportJSON, err := json.Marshal(report)
+		if err != nil {
+			logger.Fatal(err)
+		}
+		utils.Render(string(reportJSON))
+		if report.Failed > 0 {
+			os.Exit(models.BulkError{Succeeded: report.Succeeded, Failed: report.Failed}.ExitCode())
+		}
+	},
+}
+
+func Cmdbatch() *cobra.Command {
+	batchCmd.Flags().StringP("file", "f", "", "Manifest file listing the operations to run")
+	batchCmd.Flags().Int("concurrency", 1, "Number of operations run at the same time. Overrides the manifest")
+	batchCmd.Flags().Bool("continue-on-error", false, "Keep running the remaining operations after one fails. Overrides the manifest")
+	batchCmd.MarkFlagRequired("file")
+	return batchCmd
+}
+
+/* Finds the generated command named in the manifest and the operation it calls */
+func resolveCommand(rootCmd *cobra.Command) services.ResolveCommand {
+	return func(command string) (string, string, error) {
+		found, remaining, err := rootCmd.Find(strings.Fields(command))
+		if err != nil || len(remaining) > 0 || found == rootCmd {
+			return "", "", fmt.Errorf("unknown command: %v", command)
+		}
+		method, path := found.Annotations[services.MethodAnnotation], found.Annotations[services.PathAnnotation]
+		if method == "" || path == "" {
+			return "", "", fmt.Errorf("%v does not call an API operation", command)
+		}
+		return method, path, nil
+	}
+}
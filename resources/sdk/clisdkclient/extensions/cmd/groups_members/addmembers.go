@@ -5,11 +5,12 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
-	"time"
 
 	"github.com/mypurecloud/platform-client-sdk-cli/build/gc/logger"
 	"github.com/mypurecloud/platform-client-sdk-cli/build/gc/models"
+	"github.com/mypurecloud/platform-client-sdk-cli/build/gc/restclient"
 	"github.com/mypurecloud/platform-client-sdk-cli/build/gc/retry"
+	"github.com/mypurecloud/platform-client-sdk-cli/build/gc/services"
 	"github.com/mypurecloud/platform-client-sdk-cli/build/gc/utils"
 	"github.com/spf13/cobra"
 )
@@ -82,13 +83,7 @@ var AddCmd = &cobra.Command{
 		path = strings.Replace(addMembersOperation.Path, "{groupId}", fmt.Sprintf("%v", groupId), -1)
 
 		retryFunc := retry.RetryWithData(path, headerParams, data, CommandService.Post)
-		// TODO read from config file
-		retryConfig := &retry.RetryConfiguration{
-			RetryWaitMin: 5 * time.Second,
-			RetryWaitMax: 60 * time.Second,
-			RetryMax:     20,
-		}
-		results, err := retryFunc(retryConfig)
+		results, err := retryFunc(services.GetRetryConfiguration(cmd))
 		if err != nil {
 			logger.Fatal(err)
 		}
@@ -99,15 +94,14 @@ var AddCmd = &cobra.Command{
 
 func getGroupVersion(path string, headerParams map[string]string, cmd *cobra.Command) int {
 	retryFunc := CommandService.DetermineAction(getMembersOperation.Method, path, headerParams, cmd, "")
-	retryConfig := &retry.RetryConfiguration{
-		RetryWaitMin: 5 * time.Second,
-		RetryWaitMax: 60 * time.Second,
-		RetryMax:     20,
-	}
-	results, err := retryFunc(retryConfig)
+	results, err := retryFunc(services.GetRetryConfiguration(cmd))
 	if err != nil {
 		logger.Fatal(err)
 	}
+	// The group isn't retrieved with --dry-run
+	if restclient.DryRun != "" {
+		return 0
+	}
 
 	groupResult := group{}
 	err = json.Unmarshal([]byte(results), &groupResult)
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/url"
+	"os"
 	"strings"
 	"syscall"
 
@@ -25,13 +26,16 @@ const (
 	ClientCredentials           = "1"
 	ImplicitGrant               = "2"
 	PKCEGrant                   = "3"
+	DeviceGrant                 = "4"
+	SAML2BearerGrant            = "5"
+	JWTBearerGrant              = "6"
 )
 
 func isValidGrantType(t GrantType) bool {
-	return t == None || t == ClientCredentials || t == ImplicitGrant || t == PKCEGrant
+	return t == None || t == ClientCredentials || t == ImplicitGrant || t == PKCEGrant || t == DeviceGrant || t == SAML2BearerGrant || t == JWTBearerGrant
 }
 
-func constructConfig(profileName string, environment string, grantType GrantType, clientID string, clientSecret string, redirectURI string, secureLoginEnabled bool, accessToken string, proxyConf *config.ProxyConfiguration, gconf *config.GateWayConfiguration) config.Configuration {
+func constructConfig(profileName string, environment string, grantType GrantType, clientID string, clientSecret string, redirectURI string, secureLoginEnabled bool, accessToken string, orgName string, assertionFile string, assertionProcess string, credentialProcess string, proxyConf *config.ProxyConfiguration, gconf *config.GateWayConfiguration) config.Configuration {
 	c := &mocks.MockClientConfig{}
 
 	c.ProfileNameFunc = func() string {
@@ -70,6 +74,22 @@ func constructConfig(profileName string, environment string, grantType GrantType
 		return redirectURI
 	}
 
+	c.CredentialProcessFunc = func() string {
+		return credentialProcess
+	}
+
+	c.OrgNameFunc = func() string {
+		return orgName
+	}
+
+	c.AssertionFileFunc = func() string {
+		return assertionFile
+	}
+
+	c.AssertionProcessFunc = func() string {
+		return assertionProcess
+	}
+
 	c.SecureLoginEnabledFunc = func() bool {
 		return secureLoginEnabled
 	}
@@ -92,10 +112,15 @@ func constructConfig(profileName string, environment string, grantType GrantType
 		return string(jsonData)
 	}
 
+	c.RetryConfigurationFunc = func() string {
+		return ""
+	}
+
 	return c
 }
 
-func requestUserInput() config.Configuration {
+/* Asks for the details of the profile. Profiles with a credential process aren't asked for an access token or grant type, as the process provides the token */
+func requestUserInput(credentialProcess string) config.Configuration {
 	var (
 		name               string
 		environment        string
@@ -106,6 +131,9 @@ func requestUserInput() config.Configuration {
 		grantType          GrantType
 		redirectURL        url.URL
 		secureLoginEnabled = false
+		orgName            string
+		assertionFile      string
+		assertionProcess   string
 		proxyChoice        string
 		gateWayChoice      string
 	)
@@ -124,21 +152,24 @@ func requestUserInput() config.Configuration {
 		environment = "mypurecloud.com"
 	}
 
-	fmt.Print("Note: If you provide an access token, this will take precedence over any authorization grant type.\n")
-	fmt.Print("Access Token (Optional): ")
-	accessToken = readSensitiveInput()
+	grantType = None
+	if credentialProcess == "" {
+		fmt.Print("Note: If you provide an access token, this will take precedence over any authorization grant type.\n")
+		fmt.Print("Access Token (Optional): ")
+		accessToken = readSensitiveInput()
 
-	for {
-		fmt.Print("Select your authorization grant type.\n")
-		fmt.Print("\t0. None\n\t1. Client Credentials\n\t2. Implicit Grant\n\t3. PKCE Grant\nGrant Type: ")
-		_, _ = fmt.Scanln(&grantType)
+		for {
+			fmt.Print("Select your authorization grant type.\n")
+			fmt.Print("\t0. None\n\t1. Client Credentials\n\t2. Implicit Grant\n\t3. PKCE Grant\n\t4. Device Code Grant\n\t5. SAML2 Bearer Grant\n\t6. JWT Bearer Grant\nGrant Type: ")
+			_, _ = fmt.Scanln(&grantType)
 
-		if accessToken == "" && grantType == None {
-			fmt.Print("If you have not provided an access token, you must select a grant type.\n")
-			continue
-		}
-		if isValidGrantType(grantType) {
-			break
+			if accessToken == "" && grantType == None {
+				fmt.Print("If you have not provided an access token, you must select a grant type.\n")
+				continue
+			}
+			if isValidGrantType(grantType) {
+				break
+			}
 		}
 	}
 
@@ -162,6 +193,10 @@ func requestUserInput() config.Configuration {
 		fmt.Printf("Redirect URI: %s\n", redirectURL.String())
 	}
 
+	if grantType == SAML2BearerGrant || grantType == JWTBearerGrant {
+		orgName, assertionFile, assertionProcess = requestAssertionDetails(grantType)
+	}
+
 	var proxyConfig *config.ProxyConfiguration
 	for {
 		fmt.Print("Would you like to use a proxy server? [Y/N]: ")
@@ -194,14 +229,14 @@ func requestUserInput() config.Configuration {
 		}
 	}
 
-	return constructConfig(name, environment, grantType, clientID, clientSecret, redirectURL.String(), secureLoginEnabled, accessToken, proxyConfig, gateWayConfig)
+	return constructConfig(name, environment, grantType, clientID, clientSecret, redirectURL.String(), secureLoginEnabled, accessToken, orgName, assertionFile, assertionProcess, credentialProcess, proxyConfig, gateWayConfig)
 }
 
 func requestClientCreds(accessToken string, grantType GrantType) (string, string) {
 	id := ""
 	secret := ""
 
-	if grantType == ClientCredentials {
+	if grantType == ClientCredentials || grantType == SAML2BearerGrant || grantType == JWTBearerGrant {
 		if accessToken != "" {
 			fmt.Print("Client ID: ")
 			_, _ = fmt.Scanln(&id)
@@ -226,8 +261,8 @@ func requestClientCreds(accessToken string, grantType GrantType) (string, string
 
 		fmt.Print("Client Secret (Optional): ")
 		secret = readSensitiveInput()
-	} else if grantType == PKCEGrant {
-		// PKCE Grant
+	} else if grantType == PKCEGrant || grantType == DeviceGrant {
+		// PKCE Grant and Device Code Grant
 		for id == "" {
 			fmt.Print("Client ID: ")
 			_, _ = fmt.Scanln(&id)
@@ -237,6 +272,30 @@ func requestClientCreds(accessToken string, grantType GrantType) (string, string
 	return id, secret
 }
 
+// Asks where the assertion of the SAML2 bearer or JWT bearer grant is read from, and for the org name of the SAML2 bearer grant
+func requestAssertionDetails(grantType GrantType) (string, string, string) {
+	orgName := ""
+	assertionFile := ""
+	assertionProcess := ""
+	if grantType == SAML2BearerGrant {
+		for orgName == "" {
+			fmt.Print("Org Name: ")
+			_, _ = fmt.Scanln(&orgName)
+		}
+	}
+
+	for assertionFile == "" && assertionProcess == "" {
+		fmt.Print("Assertion file (leave empty to run a command instead): ")
+		assertionFile = readLine()
+		if assertionFile == "" {
+			fmt.Print("Command printing the assertion: ")
+			assertionProcess = readLine()
+		}
+	}
+
+	return orgName, assertionFile, assertionProcess
+}
+
 func requestProxyDetails() *config.ProxyConfiguration {
 	protocol := ""
 	host := ""
@@ -429,6 +488,20 @@ outerLoopLabel:
 
 }
 
+/* Reads a whole line, which can contain spaces unlike the input read with fmt.Scanln. Stdin isn't buffered so later prompts still get their input */
+func readLine() string {
+	line := make([]byte, 0)
+	b := make([]byte, 1)
+	for {
+		n, err := os.Stdin.Read(b)
+		if n == 0 || err != nil || b[0] == '\n' {
+			break
+		}
+		line = append(line, b[0])
+	}
+	return strings.TrimSpace(string(line))
+}
+
 func readSensitiveInput() string {
 	bytes, _ := term.ReadPassword(int(syscall.Stdin))
 	fmt.Println()
@@ -495,8 +568,15 @@ var createProfilesCmd = &cobra.Command{
 	Long:  `Creates a new profile`,
 
 	Run: func(cmd *cobra.Command, args []string) {
+		secretStore, _ := cmd.Flags().GetString("secret-store")
+		if secretStore != "" && secretStore != config.PlaintextSecretStore {
+			if _, err := config.NewSecretStore(secretStore); err != nil {
+				logger.Fatal(err)
+			}
+		}
 
-		newConfig := requestUserInput()
+		credentialProcess, _ := cmd.Flags().GetString("credential-process")
+		newConfig := requestUserInput(credentialProcess)
 
 		if overrideConfig(newConfig.ProfileName()) == false {
 			logger.Fatal("Exiting profile creation process")
@@ -504,6 +584,11 @@ var createProfilesCmd = &cobra.Command{
 		if newConfig.AccessToken() == "" && validateCredentials(newConfig) == false {
 			logger.Fatal("The credentials provided are not valid.")
 		}
+		if secretStore != "" {
+			if err := config.SetSecretStore(newConfig.ProfileName(), secretStore); err != nil {
+				logger.Fatal(err)
+			}
+		}
 
 		if err := config.SaveConfig(newConfig); err != nil {
 			logger.Fatal(err)
@@ -0,0 +1,46 @@
+package profiles
+
+import (
+	"fmt"
+
+	"github.com/mypurecloud/platform-client-sdk-cli/build/gc/config"
+	"github.com/mypurecloud/platform-client-sdk-cli/build/gc/logger"
+
+	"github.com/spf13/cobra"
+)
+
+var migrateSecretsCmd = &cobra.Command{
+	Use:   "migrate-secrets <store> [profileName]",
+	Short: "Moves the secrets of a profile to a secret store",
+	Long: `Moves the client secret, tokens and proxy and gateway passwords of a profile out of the config file to a secret store, leaving references to them in the profile.
+The default profile is migrated if no name is provided. Supported stores:
+  keychain       The OS keychain: the macOS Keychain, the Windows Credential Manager or the Secret Service on Linux
+  file           A file next to the config file encrypted with age, using the passphrase in $GENESYSCLOUD_SECRET_PASSPHRASE or asked for,
+                 or the keys of the age identity file in $GENESYSCLOUD_SECRET_IDENTITY
+  helper:<name>  The credential helper gc-credential-<name>, or the helper at the path given, following the protocol of git's credential helpers
+  plaintext      The config file, moving the secrets back from the store they're kept in`,
+	Args: cobra.RangeArgs(1, 2),
+
+	Run: func(cmd *cobra.Command, args []string) {
+		storeName := args[0]
+		var profileNames []string
+		if all, _ := cmd.Flags().GetBool("all"); all {
+			profileNames = ListProfileNames()
+		} else if len(args) == 2 {
+			profileNames = []string{args[1]}
+		} else {
+			profileName, _ := cmd.Root().Flags().GetString("profile")
+			profileNames = []string{profileName}
+		}
+
+		for _, profileName := range profileNames {
+			if _, err := config.GetConfig(profileName); err != nil {
+				logger.Fatal(err)
+			}
+			if err := config.MigrateSecrets(profileName, storeName); err != nil {
+				logger.Fatal(err)
+			}
+			fmt.Printf("Secrets of profile %s moved to the %s secret store.\n", profileName, storeName)
+		}
+	},
+}
@@ -14,5 +14,9 @@ func Cmdprofiles() *cobra.Command {
 	profileCmd.AddCommand(currentProfileCmd)
 	profileCmd.AddCommand(createProfilesCmd)
 	profileCmd.AddCommand(listProfilesCmd)
+	profileCmd.AddCommand(migrateSecretsCmd)
+	createProfilesCmd.Flags().String("credential-process", "", "Command run to obtain the profile's access token instead of authorizing with an OAuth client")
+	createProfilesCmd.Flags().String("secret-store", "", "Secret store the profile's secrets are kept in instead of the config file: keychain, file or helper:<name>")
+	migrateSecretsCmd.Flags().Bool("all", false, "Migrate the secrets of every profile")
 	return profileCmd
 }
@@ -0,0 +1,192 @@
+package retry
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/mypurecloud/platform-client-sdk-cli/build/gc/config"
+	"github.com/mypurecloud/platform-client-sdk-cli/build/gc/logger"
+	retrypolicy "github.com/mypurecloud/platform-client-sdk-cli/build/gc/retry"
+	"github.com/mypurecloud/platform-client-sdk-cli/build/gc/utils"
+	"github.com/spf13/cobra"
+)
+
+var retryCmd = &cobra.Command{
+	Use:   "retry",
+	Short: "Manages the retry policy for the CLI",
+	Long:  `Manages the retry policy for the CLI. Requests are retried with an exponential backoff between the minimum and maximum wait times`,
+}
+
+func Cmdretry() *cobra.Command {
+	setCmd.Flags().Int("wait-min", 0, "Minimum number of seconds to wait between retries")
+	setCmd.Flags().Int("wait-max", 0, "Maximum number of seconds to wait between retries")
+	setCmd.Flags().Int("max", 0, "Maximum number of times a request is retried. 0 disables retries")
+
+	retryCmd.AddCommand(setCmd)
+	retryCmd.AddCommand(onCmd)
+	retryCmd.AddCommand(offCmd)
+	retryCmd.AddCommand(clearCmd)
+	retryCmd.AddCommand(resetCmd)
+	retryCmd.AddCommand(statusCmd)
+	return retryCmd
+}
+
+var setCmd = &cobra.Command{
+	Use:   "set",
+	Short: "Sets the retry wait times and maximum number of retries",
+	Long:  `Sets the retry wait times and maximum number of retries`,
+	Args:  cobra.NoArgs,
+
+	Run: func(cmd *cobra.Command, args []string) {
+		c, retryConf := getRetryConfiguration(cmd)
+		for flag, value := range map[string]**int{"wait-min": &retryConf.WaitMin, "wait-max": &retryConf.WaitMax, "max": &retryConf.Max} {
+			if !cmd.Flags().Changed(flag) {
+				continue
+			}
+			setting, _ := cmd.Flags().GetInt(flag)
+			if setting < 0 {
+				logger.Fatal(fmt.Sprintf("--%v must not be negative", flag))
+			}
+			*value = &setting
+		}
+		updateRetryConfiguration(c, retryConf)
+	},
+}
+
+var onCmd = &cobra.Command{
+	Use:   "on [statusCode]...",
+	Short: "Always retries responses with the given status codes",
+	Long:  `Always retries responses with the given status codes`,
+	Args:  cobra.MinimumNArgs(1),
+
+	Run: func(cmd *cobra.Command, args []string) {
+		setStatusRules(cmd, args, true)
+	},
+}
+
+var offCmd = &cobra.Command{
+	Use:   "off [statusCode]...",
+	Short: "Never retries responses with the given status codes",
+	Long:  `Never retries responses with the given status codes`,
+	Args:  cobra.MinimumNArgs(1),
+
+	Run: func(cmd *cobra.Command, args []string) {
+		setStatusRules(cmd, args, false)
+	},
+}
+
+var clearCmd = &cobra.Command{
+	Use:   "clear [statusCode]...",
+	Short: "Removes the rules for the given status codes",
+	Long:  `Removes the rules for the given status codes so the default retry behaviour applies to them`,
+	Args:  cobra.MinimumNArgs(1),
+
+	Run: func(cmd *cobra.Command, args []string) {
+		c, retryConf := getRetryConfiguration(cmd)
+		for _, statusCode := range parseStatusCodes(args) {
+			delete(retryConf.StatusRules, statusCode)
+		}
+		updateRetryConfiguration(c, retryConf)
+	},
+}
+
+var resetCmd = &cobra.Command{
+	Use:   "reset",
+	Short: "Resets the retry policy to the defaults",
+	Long:  resetLong(),
+	Args:  cobra.NoArgs,
+
+	Run: func(cmd *cobra.Command, args []string) {
+		c, _ := getRetryConfiguration(cmd)
+		waitMin, waitMax, max := defaultRetryPolicy()
+		updateRetryConfiguration(c, &config.RetryConfiguration{
+			WaitMin:     &waitMin,
+			WaitMax:     &waitMax,
+			Max:         &max,
+			StatusRules: map[int]bool{},
+		})
+	},
+}
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Shows the retry policy",
+	Long:  `Shows the retry policy of the profile. Wait times are in seconds`,
+	Args:  cobra.NoArgs,
+
+	Run: func(cmd *cobra.Command, args []string) {
+		_, retryConf := getRetryConfiguration(cmd)
+		waitMin, waitMax, max := defaultRetryPolicy()
+		if retryConf.WaitMin == nil {
+			retryConf.WaitMin = &waitMin
+		}
+		if retryConf.WaitMax == nil {
+			retryConf.WaitMax = &waitMax
+		}
+		if retryConf.Max == nil {
+			retryConf.Max = &max
+		}
+		jsonData, _ := json.MarshalIndent(retryConf, "", "  ")
+		utils.Render(string(jsonData))
+	},
+}
+
+/* Returns the retry policy used when the profile doesn't set one, with the wait times in seconds as they are kept in the profile */
+func defaultRetryPolicy() (int, int, int) {
+	defaults := retrypolicy.DefaultRetryConfiguration()
+	return int(defaults.RetryWaitMin / time.Second), int(defaults.RetryWaitMax / time.Second), defaults.RetryMax
+}
+
+func resetLong() string {
+	waitMin, waitMax, max := defaultRetryPolicy()
+	return fmt.Sprintf("Resets the retry policy to the defaults: wait between %v and %v seconds, retry up to %v times and remove all status code rules", waitMin, waitMax, max)
+}
+
+func getRetryConfiguration(cmd *cobra.Command) (config.Configuration, *config.RetryConfiguration) {
+	profileName, _ := cmd.Root().Flags().GetString("profile")
+	c, err := config.GetConfig(profileName)
+	if err != nil {
+		logger.Fatal(err)
+	}
+
+	retryConf := &config.RetryConfiguration{}
+	if c.RetryConfiguration() != "" {
+		err = json.Unmarshal([]byte(c.RetryConfiguration()), retryConf)
+		if err != nil {
+			logger.Fatal(err)
+		}
+	}
+	if retryConf.StatusRules == nil {
+		retryConf.StatusRules = make(map[int]bool)
+	}
+	return c, retryConf
+}
+
+func setStatusRules(cmd *cobra.Command, args []string, retry bool) {
+	c, retryConf := getRetryConfiguration(cmd)
+	for _, statusCode := range parseStatusCodes(args) {
+		retryConf.StatusRules[statusCode] = retry
+	}
+	updateRetryConfiguration(c, retryConf)
+}
+
+func updateRetryConfiguration(c config.Configuration, retryConf *config.RetryConfiguration) {
+	err := config.UpdateRetryConfiguration(c, retryConf)
+	if err != nil {
+		logger.Fatal(err)
+	}
+}
+
+func parseStatusCodes(args []string) []int {
+	statusCodes := make([]int, 0, len(args))
+	for _, arg := range args {
+		statusCode, err := strconv.Atoi(arg)
+		if err != nil || statusCode < 100 || statusCode > 599 {
+			logger.Fatal(fmt.Sprintf("Invalid HTTP status code: %v", arg))
+		}
+		statusCodes = append(statusCodes, statusCode)
+	}
+	return statusCodes
+}
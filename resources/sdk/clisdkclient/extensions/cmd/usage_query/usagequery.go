@@ -9,6 +9,7 @@ import (
 
 	"github.com/mypurecloud/platform-client-sdk-cli/build/gc/logger"
 	"github.com/mypurecloud/platform-client-sdk-cli/build/gc/models"
+	"github.com/mypurecloud/platform-client-sdk-cli/build/gc/services"
 	"github.com/mypurecloud/platform-client-sdk-cli/build/gc/utils"
 
 	"github.com/spf13/cobra"
@@ -45,7 +46,7 @@ var queryUsageCmd = &cobra.Command{
 
 		retryFunc := CommandService.DetermineAction(usageQueryOperation.Method, usageQueryOperation.Path, headerParams, cmd, "")
 
-		results, err := retryFunc(nil)
+		results, err := retryFunc(services.GetRetryConfiguration(cmd))
 		if err != nil {
 			logger.Fatal(err)
 		}
@@ -65,7 +66,7 @@ var queryUsageCmd = &cobra.Command{
 				targetURI := strings.Replace(path, "{executionId}", fmt.Sprintf("%v", usageSubmitResponse.ExecutionID), -1)
 				retryFunc := CommandService.DetermineAction(usageQueryResultsOperation.Method, targetURI, headerParams, cmd, "")
 
-				rawData, commandErr := retryFunc(nil)
+				rawData, commandErr := retryFunc(services.GetRetryConfiguration(cmd))
 				if commandErr != nil {
 					logger.Fatal(commandErr)
 				}
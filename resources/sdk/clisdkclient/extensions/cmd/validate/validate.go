@@ -0,0 +1,72 @@
+package validate
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+
+	"github.com/mypurecloud/platform-client-sdk-cli/build/gc/logger"
+	"github.com/mypurecloud/platform-client-sdk-cli/build/gc/utils"
+	"github.com/mypurecloud/platform-client-sdk-cli/build/gc/validation"
+	"github.com/spf13/cobra"
+)
+
+// result is the outcome of validating one of the request bodies
+type result struct {
+	File   string             `json:"file"`
+	Valid  bool               `json:"valid"`
+	Errors []validation.Error `json:"errors,omitempty"`
+}
+
+var validateCmd = &cobra.Command{
+	Use:   "validate <command>",
+	Short: "Checks request bodies against the body schema of a command",
+	Long: `Checks the request bodies read from --file, --directory or stdin against the body schema of the named command, e.g. "gc validate users create -f user.json".
+Unknown fields, missing required fields, values of the wrong type and values that aren't one of an enum's values are reported with the JSON pointer of the value.
+The exit code is 1 if any of the bodies aren't valid`,
+	Args: cobra.MinimumNArgs(1),
+
+	Run: func(cmd *cobra.Command, args []string) {
+		logger.InitLogger(cmd)
+
+		command, _, err := cmd.Root().Find(args)
+		if err != nil || command == cmd.Root() {
+			logger.Fatalf("Unknown command: %v\n", strings.Join(args, " "))
+		}
+		schema := utils.BodySchema(command)
+		if schema == nil {
+			logger.Fatalf("%v does not take a request body\n", command.CommandPath())
+		}
+
+		// The command's body model is only known when it runs, so a model of no fields stands in for it for a list of bodies to be split
+		if schema.Type != "array" {
+			utils.RequestBodyModel = struct{}{}
+		}
+
+		results := make([]result, 0)
+		valid := true
+		for _, file := range utils.ReadInputFiles(cmd) {
+			errors := validation.Validate(file.Data, schema)
+			results = append(results, result{File: file.Name, Valid: len(errors) == 0, Errors: errors})
+			valid = valid && len(errors) == 0
+		}
+
+		resultsJSON, err := json.Marshal(results)
+		if err != nil {
+			logger.Fatal(err)
+		}
+		utils.Render(string(resultsJSON))
+		if !valid {
+			os.Exit(1)
+		}
+	},
+}
+
+func Cmdvalidate() *cobra.Command {
+	validateCmd.Flags().StringP("file", "f", "", "File name containing the request body")
+	validateCmd.Flags().StringP("directory", "d", "", "Directory path with files containing request bodies")
+	validateCmd.Flags().StringArray("var", []string{}, "Variable given as key=value for rendering request bodies as Go templates. Can be repeated")
+	validateCmd.Flags().StringArray("var-file", []string{}, "YAML or JSON file of variables for rendering request bodies as Go templates. Can be repeated")
+	validateCmd.Flags().Bool("render-body", false, "Render request bodies as Go templates with environment variables, without --var or --var-file")
+	return validateCmd
+}
@@ -6,6 +6,8 @@ import (
 
 	"github.com/mypurecloud/platform-client-sdk-cli/build/gc/models"
 
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/spf13/viper"
@@ -20,6 +22,10 @@ type Configuration interface {
 	ClientID() string
 	ClientSecret() string
 	RedirectURI() string
+	CredentialProcess() string
+	OrgName() string
+	AssertionFile() string
+	AssertionProcess() string
 	OAuthTokenData() string
 	AccessToken() string
 	LogFilePath() string
@@ -28,6 +34,7 @@ type Configuration interface {
 	AutoPaginationEnabled() bool
 	ProxyConfiguration() string
 	GateWayConfiguration() string
+	RetryConfiguration() string
 	fmt.Stringer
 }
 
@@ -46,6 +53,7 @@ type configuration struct {
 	autoPaginationEnabled bool
 	proxyConfiguration    string
 	gatewayConfiguration  string
+	retryConfiguration    string
 }
 
 type GateWayConfiguration struct {
@@ -66,11 +74,21 @@ type ProxyConfiguration struct {
 	PathParams map[string]string
 }
 
+// RetryConfiguration is the retry policy of a profile. Unset values fall back to the CLI defaults
+type RetryConfiguration struct {
+	WaitMin     *int         `json:"waitMin,omitempty"`
+	WaitMax     *int         `json:"waitMax,omitempty"`
+	Max         *int         `json:"max,omitempty"`
+	StatusRules map[int]bool `json:"statusRules"`
+}
+
 var (
 	Environment    string
 	ClientId       string
 	ClientSecret   string
 	AccessToken    string
+	RetryMax       = -1
+	NoRetry        bool
 	RegionMappings = map[string]string{
 		"us-east-1":      "mypurecloud.com",
 		"eu-west-1":      "mypurecloud.ie",
@@ -118,7 +136,7 @@ func (c *configuration) ClientSecret() string {
 		return ClientSecret
 	}
 
-	return viper.GetString(fmt.Sprintf("%s.client_secret", c.profileName))
+	return getSecret(c.profileName, "client_secret")
 }
 
 func (c *configuration) AccessToken() string {
@@ -126,7 +144,7 @@ func (c *configuration) AccessToken() string {
 		return AccessToken
 	}
 
-	return viper.GetString(fmt.Sprintf("%s.access_token", c.profileName))
+	return getSecret(c.profileName, "access_token")
 }
 
 func (c *configuration) SecureLoginEnabled() bool {
@@ -137,9 +155,29 @@ func (c *configuration) RedirectURI() string {
 	return viper.GetString(fmt.Sprintf("%s.redirect_uri", c.profileName))
 }
 
+// CredentialProcess is the command run to obtain an access token instead of authorizing with the OAuth client
+func (c *configuration) CredentialProcess() string {
+	return viper.GetString(fmt.Sprintf("%s.credential_process", c.profileName))
+}
+
+// OrgName is the name of the org the SAML2 bearer grant authorizes with
+func (c *configuration) OrgName() string {
+	return viper.GetString(fmt.Sprintf("%s.org_name", c.profileName))
+}
+
+// AssertionFile is the file the assertion of the SAML2 bearer and JWT bearer grants is read from
+func (c *configuration) AssertionFile() string {
+	return viper.GetString(fmt.Sprintf("%s.assertion_file", c.profileName))
+}
+
+// AssertionProcess is the command run to obtain the assertion of the SAML2 bearer and JWT bearer grants, if it isn't read from a file
+func (c *configuration) AssertionProcess() string {
+	return viper.GetString(fmt.Sprintf("%s.assertion_process", c.profileName))
+}
+
 // OAuthTokenData is the raw OAuth token data returned from the login API call combined with the access token expiry timestamp
 func (c *configuration) OAuthTokenData() string {
-	return viper.GetString(fmt.Sprintf("%s.oauth_token_data", c.profileName))
+	return getSecret(c.profileName, "oauth_token_data")
 }
 
 // Environment is the Genesys Cloud Environment the CLI will talk to
@@ -188,6 +226,11 @@ func (c *configuration) GateWayConfiguration() string {
 	return getGateWayConfig(c.profileName)
 }
 
+// RetryConfiguration is the retry policy set for the profile
+func (c *configuration) RetryConfiguration() string {
+	return getRetryConfig(c.profileName)
+}
+
 func getProxyConfig(profileName string) string {
 	// proxy
 	protocol := viper.Get(fmt.Sprintf("%s.proxy_protocol", profileName))
@@ -208,7 +251,7 @@ func getProxyConfig(profileName string) string {
 
 		if userName != nil {
 			proxyconf.UserName = viper.GetString(fmt.Sprintf("%s.proxy_username", profileName))
-			proxyconf.Password = viper.GetString(fmt.Sprintf("%s.proxy_password", profileName))
+			proxyconf.Password = getSecret(profileName, "proxy_password")
 		}
 		jsonData, _ := json.MarshalIndent(proxyconf, "", "")
 		return string(jsonData)
@@ -237,7 +280,7 @@ func getGateWayConfig(profileName string) string {
 
 		if userName != nil {
 			gconf.UserName = viper.GetString(fmt.Sprintf("%s.gateway_username", profileName))
-			gconf.Password = viper.GetString(fmt.Sprintf("%s.gateway_password", profileName))
+			gconf.Password = getSecret(profileName, "gateway_password")
 		}
 		jsonData, _ := json.MarshalIndent(gconf, "", "")
 		return string(jsonData)
@@ -246,9 +289,67 @@ func getGateWayConfig(profileName string) string {
 	}
 }
 
+func getRetryConfig(profileName string) string {
+	retryConf := RetryConfiguration{}
+	if viper.IsSet(fmt.Sprintf("%s.retry_wait_min", profileName)) {
+		waitMin := viper.GetInt(fmt.Sprintf("%s.retry_wait_min", profileName))
+		retryConf.WaitMin = &waitMin
+	}
+	if viper.IsSet(fmt.Sprintf("%s.retry_wait_max", profileName)) {
+		waitMax := viper.GetInt(fmt.Sprintf("%s.retry_wait_max", profileName))
+		retryConf.WaitMax = &waitMax
+	}
+	if viper.IsSet(fmt.Sprintf("%s.retry_max", profileName)) {
+		max := viper.GetInt(fmt.Sprintf("%s.retry_max", profileName))
+		retryConf.Max = &max
+	}
+	retryConf.StatusRules = parseStatusRules(viper.GetString(fmt.Sprintf("%s.retry_status_rules", profileName)))
+
+	if retryConf.WaitMin == nil && retryConf.WaitMax == nil && retryConf.Max == nil && len(retryConf.StatusRules) == 0 {
+		return ""
+	}
+	jsonData, _ := json.Marshal(retryConf)
+	return string(jsonData)
+}
+
+// The status rules are stored as a comma separated list of statusCode:retry pairs, e.g. "409:true, 503:false"
+func getStatusRules(statusRules map[int]bool) string {
+	statusCodes := make([]int, 0, len(statusRules))
+	for statusCode := range statusRules {
+		statusCodes = append(statusCodes, statusCode)
+	}
+	sort.Ints(statusCodes)
+
+	rules := make([]string, 0, len(statusCodes))
+	for _, statusCode := range statusCodes {
+		rules = append(rules, fmt.Sprintf("%d:%v", statusCode, statusRules[statusCode]))
+	}
+	return strings.Join(rules, ", ")
+}
+
+func parseStatusRules(statusRulesStr string) map[int]bool {
+	statusRules := make(map[int]bool)
+	for _, rule := range strings.Split(statusRulesStr, ",") {
+		kv := strings.SplitN(rule, ":", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		statusCode, err := strconv.Atoi(strings.TrimSpace(kv[0]))
+		if err != nil {
+			continue
+		}
+		retry, err := strconv.ParseBool(strings.TrimSpace(kv[1]))
+		if err != nil {
+			continue
+		}
+		statusRules[statusCode] = retry
+	}
+	return statusRules
+}
+
 func (c *configuration) String() string {
-	return fmt.Sprintf(`{"profileName": "%s", "environment": "%s", "logFilePath": "%s", "loggingEnabled": "%v", "grantType": "%s", "clientName": "%s", "clientSecret": "%s", "secureLoginEnabled": "%v", "redirectURI": "%s", "accessToken": "%s", "autoPaginationEnabled": "%v" , "proxyConfiguration" : "%v", "gatewayConfiguration": "%v"}`, c.ProfileName(), c.Environment(), c.LogFilePath(), c.LoggingEnabled(), c.GrantType(), c.ClientID(),
-		c.ClientSecret(), c.SecureLoginEnabled(), c.RedirectURI(), c.AccessToken(), c.AutoPaginationEnabled(), c.proxyString(), c.gateWayString())
+	return fmt.Sprintf(`{"profileName": "%s", "environment": "%s", "logFilePath": "%s", "loggingEnabled": "%v", "grantType": "%s", "clientName": "%s", "clientSecret": "%s", "secureLoginEnabled": "%v", "redirectURI": "%s", "accessToken": "%s", "autoPaginationEnabled": "%v" , "proxyConfiguration" : "%v", "gatewayConfiguration": "%v", "retryConfiguration": %s}`, c.ProfileName(), c.Environment(), c.LogFilePath(), c.LoggingEnabled(), c.GrantType(), c.ClientID(),
+		c.ClientSecret(), c.SecureLoginEnabled(), c.RedirectURI(), c.AccessToken(), c.AutoPaginationEnabled(), c.proxyString(), c.gateWayString(), c.retryString())
 }
 
 func (c *configuration) proxyString() string {
@@ -275,6 +376,14 @@ func (c *configuration) gateWayString() string {
 		gateWayConfig.Protocol, gateWayConfig.Host, gateWayConfig.Port, gateWayConfig.UserName, gateWayConfig.Password, getPathParams(gateWayConfig.PathParams))
 }
 
+func (c *configuration) retryString() string {
+	retryConf := c.RetryConfiguration()
+	if retryConf == "" {
+		return `""`
+	}
+	return retryConf
+}
+
 func getPathParams(pathParms map[string]string) string {
 
 	pathParamsStr := "{"
@@ -369,6 +478,9 @@ func GetConfig(profileName string) (Configuration, error) {
 	if profile == nil {
 		return nil, fmt.Errorf("The profile named %s passed can not be located in the config file.", profileName)
 	}
+	if err := checkSecrets(profileName); err != nil {
+		return nil, err
+	}
 
 	return &configuration{profileName: profileName,
 		grantType:             viper.GetString(fmt.Sprintf("%s.grant_type", profileName)),
@@ -384,6 +496,7 @@ func GetConfig(profileName string) (Configuration, error) {
 		secureLoginEnabled:    viper.GetBool(fmt.Sprintf("%s.secure_login_enabled", profileName)),
 		proxyConfiguration:    getProxyConfig(profileName),
 		gatewayConfiguration:  getGateWayConfig(profileName),
+		retryConfiguration:    getRetryConfig(profileName),
 	}, nil
 }
 
@@ -420,6 +533,7 @@ func ListConfigs() ([]configuration, error) {
 			secureLoginEnabled:    viper.GetBool(fmt.Sprintf("%s.secure_login_enabled", profileName)),
 			proxyConfiguration:    getProxyConfig(profileName),
 			gatewayConfiguration:  getGateWayConfig(profileName),
+			retryConfiguration:    getRetryConfig(profileName),
 		})
 	}
 
@@ -469,6 +583,15 @@ func UpdateGateWayConfiguration(c Configuration, gateWayConf *GateWayConfigurati
 	}, nil, nil, nil)
 }
 
+func UpdateRetryConfiguration(c Configuration, retryConf *RetryConfiguration) error {
+	jsonData, _ := json.Marshal(retryConf)
+
+	return updateConfig(configuration{
+		profileName:        c.ProfileName(),
+		retryConfiguration: string(jsonData),
+	}, nil, nil, nil)
+}
+
 func SetLoggingEnabled(c Configuration, loggingEnabled bool) error {
 	return updateConfig(configuration{
 		profileName: c.ProfileName(),
@@ -541,7 +664,9 @@ func updateConfig(c configuration, loggingEnabled *bool, autoPaginationEnabled *
 		viper.Set(fmt.Sprintf("%s.client_credentials", c.profileName), c.clientID)
 	}
 	if c.clientSecret != "" {
-		viper.Set(fmt.Sprintf("%s.client_secret", c.profileName), c.clientSecret)
+		if err := writeSecret(c.profileName, "client_secret", c.clientSecret); err != nil {
+			return err
+		}
 	}
 	if c.redirectURI != "" {
 		viper.Set(fmt.Sprintf("%s.redirect_uri", c.profileName), c.redirectURI)
@@ -550,10 +675,14 @@ func updateConfig(c configuration, loggingEnabled *bool, autoPaginationEnabled *
 		viper.Set(fmt.Sprintf("%s.environment", c.profileName), c.environment)
 	}
 	if c.oAuthTokenData != "" {
-		viper.Set(fmt.Sprintf("%s.oauth_token_data", c.profileName), c.oAuthTokenData)
+		if err := writeSecret(c.profileName, "oauth_token_data", c.oAuthTokenData); err != nil {
+			return err
+		}
 	}
 	if c.accessToken != "" {
-		viper.Set(fmt.Sprintf("%s.access_token", c.profileName), c.accessToken)
+		if err := writeSecret(c.profileName, "access_token", c.accessToken); err != nil {
+			return err
+		}
 	}
 	if c.logFilePath != "" {
 		viper.Set(fmt.Sprintf("%s.log_file_path", c.profileName), c.logFilePath)
@@ -577,13 +706,14 @@ func updateConfig(c configuration, loggingEnabled *bool, autoPaginationEnabled *
 		port := fmt.Sprintf("%s.proxy_port", c.ProfileName())
 		host := fmt.Sprintf("%s.proxy_host", c.ProfileName())
 		username := fmt.Sprintf("%s.proxy_username", c.ProfileName())
-		password := fmt.Sprintf("%s.proxy_password", c.ProfileName())
 
 		viper.Set(protocol, proxyConfig.Protocol)
 		viper.Set(port, proxyConfig.Port)
 		viper.Set(host, proxyConfig.Host)
 		viper.Set(username, proxyConfig.UserName)
-		viper.Set(password, proxyConfig.Password)
+		if err := writeSecret(c.ProfileName(), "proxy_password", proxyConfig.Password); err != nil {
+			return err
+		}
 		viper.Set(fmt.Sprintf("%s.proxy_pathparams", c.ProfileName()), getPathParams(proxyConfig.PathParams))
 
 	}
@@ -597,15 +727,34 @@ func updateConfig(c configuration, loggingEnabled *bool, autoPaginationEnabled *
 		port := fmt.Sprintf("%s.gateway_port", c.ProfileName())
 		host := fmt.Sprintf("%s.gateway_host", c.ProfileName())
 		username := fmt.Sprintf("%s.gateway_username", c.ProfileName())
-		password := fmt.Sprintf("%s.gateway_password", c.ProfileName())
 		viper.Set(protocol, gConfig.Protocol)
 		viper.Set(port, gConfig.Port)
 		viper.Set(host, gConfig.Host)
 		viper.Set(username, gConfig.UserName)
-		viper.Set(password, gConfig.Password)
+		if err := writeSecret(c.ProfileName(), "gateway_password", gConfig.Password); err != nil {
+			return err
+		}
 		viper.Set(fmt.Sprintf("%s.gateway_pathparams", c.ProfileName()), getPathParams(gConfig.PathParams))
 	}
 
+	if c.retryConfiguration != "" {
+		var retryConf RetryConfiguration
+		_ = json.Unmarshal([]byte(c.retryConfiguration), &retryConf)
+
+		if retryConf.WaitMin != nil {
+			viper.Set(fmt.Sprintf("%s.retry_wait_min", c.ProfileName()), *retryConf.WaitMin)
+		}
+		if retryConf.WaitMax != nil {
+			viper.Set(fmt.Sprintf("%s.retry_wait_max", c.ProfileName()), *retryConf.WaitMax)
+		}
+		if retryConf.Max != nil {
+			viper.Set(fmt.Sprintf("%s.retry_max", c.ProfileName()), *retryConf.Max)
+		}
+		if retryConf.StatusRules != nil {
+			viper.Set(fmt.Sprintf("%s.retry_status_rules", c.ProfileName()), getStatusRules(retryConf.StatusRules))
+		}
+	}
+
 	if viper.ConfigFileUsed() == "" {
 		return nil
 	}
@@ -616,13 +765,31 @@ func updateConfig(c configuration, loggingEnabled *bool, autoPaginationEnabled *
 func writeConfig(c Configuration, data *models.OAuthTokenData, logFilePath string, loggingEnabled *bool, autoPaginationEnabled *bool) error {
 	viper.Set(fmt.Sprintf("%s.grant_type", c.ProfileName()), c.GrantType())
 	viper.Set(fmt.Sprintf("%s.client_credentials", c.ProfileName()), c.ClientID())
-	viper.Set(fmt.Sprintf("%s.client_secret", c.ProfileName()), c.ClientSecret())
+	if err := writeSecret(c.ProfileName(), "client_secret", c.ClientSecret()); err != nil {
+		return err
+	}
 	viper.Set(fmt.Sprintf("%s.redirect_uri", c.ProfileName()), c.RedirectURI())
 	viper.Set(fmt.Sprintf("%s.environment", c.ProfileName()), c.Environment())
-	viper.Set(fmt.Sprintf("%s.access_token", c.ProfileName()), c.AccessToken())
+	if err := writeSecret(c.ProfileName(), "access_token", c.AccessToken()); err != nil {
+		return err
+	}
 	viper.Set(fmt.Sprintf("%s.secure_login_enabled", c.ProfileName()), c.SecureLoginEnabled())
+	if c.OrgName() != "" {
+		viper.Set(fmt.Sprintf("%s.org_name", c.ProfileName()), c.OrgName())
+	}
+	if c.AssertionFile() != "" {
+		viper.Set(fmt.Sprintf("%s.assertion_file", c.ProfileName()), c.AssertionFile())
+	}
+	if c.AssertionProcess() != "" {
+		viper.Set(fmt.Sprintf("%s.assertion_process", c.ProfileName()), c.AssertionProcess())
+	}
+	if c.CredentialProcess() != "" {
+		viper.Set(fmt.Sprintf("%s.credential_process", c.ProfileName()), c.CredentialProcess())
+	}
 	if data != nil {
-		viper.Set(fmt.Sprintf("%s.oauth_token_data", c.ProfileName()), data.String())
+		if err := writeSecret(c.ProfileName(), "oauth_token_data", data.String()); err != nil {
+			return err
+		}
 	}
 	if logFilePath != "" {
 		viper.Set(fmt.Sprintf("%s.log_file_path", c.ProfileName()), logFilePath)
@@ -643,12 +810,13 @@ func writeConfig(c Configuration, data *models.OAuthTokenData, logFilePath strin
 		port := fmt.Sprintf("%s.proxy_port", c.ProfileName())
 		host := fmt.Sprintf("%s.proxy_host", c.ProfileName())
 		username := fmt.Sprintf("%s.proxy_username", c.ProfileName())
-		password := fmt.Sprintf("%s.proxy_password", c.ProfileName())
 		viper.Set(protocol, proxyConfig.Protocol)
 		viper.Set(port, proxyConfig.Port)
 		viper.Set(host, proxyConfig.Host)
 		viper.Set(username, proxyConfig.UserName)
-		viper.Set(password, proxyConfig.Password)
+		if err := writeSecret(c.ProfileName(), "proxy_password", proxyConfig.Password); err != nil {
+			return err
+		}
 		viper.Set(fmt.Sprintf("%s.proxy_pathparams", c.ProfileName()), getPathParams(proxyConfig.PathParams))
 	}
 
@@ -661,12 +829,13 @@ func writeConfig(c Configuration, data *models.OAuthTokenData, logFilePath strin
 		port := fmt.Sprintf("%s.gateway_port", c.ProfileName())
 		host := fmt.Sprintf("%s.gateway_host", c.ProfileName())
 		username := fmt.Sprintf("%s.gateway_username", c.ProfileName())
-		password := fmt.Sprintf("%s.gateway_password", c.ProfileName())
 		viper.Set(protocol, gConfig.Protocol)
 		viper.Set(port, gConfig.Port)
 		viper.Set(host, gConfig.Host)
 		viper.Set(username, gConfig.UserName)
-		viper.Set(password, gConfig.Password)
+		if err := writeSecret(c.ProfileName(), "gateway_password", gConfig.Password); err != nil {
+			return err
+		}
 		viper.Set(fmt.Sprintf("%s.gateway_pathparams", c.ProfileName()), getPathParams(gConfig.PathParams))
 	}
 
@@ -0,0 +1,268 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"filippo.io/age"
+	"golang.org/x/term"
+)
+
+const (
+	// SecretPassphraseEnv is the passphrase of the secrets file, asked for on the terminal if it isn't set
+	SecretPassphraseEnv = "GENESYSCLOUD_SECRET_PASSPHRASE"
+	// SecretIdentityEnv is the path of an age identity file, whose keys the secrets file is encrypted with instead of a passphrase
+	SecretIdentityEnv = "GENESYSCLOUD_SECRET_IDENTITY"
+)
+
+// Work factor of the scrypt key derivation of passphrases, lowered by tests
+var scryptWorkFactor = 18
+
+/*
+fileSecretStore keeps secrets in a file encrypted with age, either with a passphrase or with the keys of an age identity file.
+The file holds a JSON object of the secrets, which is decrypted when the first secret is read and written again for each change
+*/
+type fileSecretStore struct {
+	path string
+	// Guards the secrets, which are loaded and saved by configurations used concurrently
+	lock    sync.Mutex
+	secrets map[string]string
+	// The passphrase the file was decrypted with, used to encrypt it again
+	passphrase string
+}
+
+func (s *fileSecretStore) Get(key string) (string, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if err := s.load(); err != nil {
+		return "", err
+	}
+	secret, ok := s.secrets[key]
+	if !ok {
+		return "", fmt.Errorf("%s is not in %s", key, s.path)
+	}
+	return secret, nil
+}
+
+func (s *fileSecretStore) Set(key string, value string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if err := s.load(); err != nil {
+		return err
+	}
+	secrets := s.copySecrets()
+	secrets[key] = value
+	return s.save(secrets)
+}
+
+func (s *fileSecretStore) Delete(key string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if err := s.load(); err != nil {
+		return err
+	}
+	if _, ok := s.secrets[key]; !ok {
+		return nil
+	}
+	secrets := s.copySecrets()
+	delete(secrets, key)
+	return s.save(secrets)
+}
+
+/* Changes are made to a copy of the secrets, so they're only kept once they've been saved */
+func (s *fileSecretStore) copySecrets() map[string]string {
+	secrets := make(map[string]string, len(s.secrets))
+	for key, value := range s.secrets {
+		secrets[key] = value
+	}
+	return secrets
+}
+
+func (s *fileSecretStore) load() error {
+	if s.secrets != nil {
+		return nil
+	}
+	s.secrets = make(map[string]string)
+
+	file, err := os.Open(s.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	identities, err := s.identities()
+	if err != nil {
+		s.secrets = nil
+		return err
+	}
+	decrypted, err := age.Decrypt(file, identities...)
+	if err != nil {
+		s.secrets = nil
+		return fmt.Errorf("Error decrypting %s: %v", s.path, err)
+	}
+	data, err := io.ReadAll(decrypted)
+	if err != nil {
+		s.secrets = nil
+		return fmt.Errorf("Error decrypting %s: %v", s.path, err)
+	}
+	if err := json.Unmarshal(data, &s.secrets); err != nil {
+		s.secrets = nil
+		return fmt.Errorf("Error reading %s: %v", s.path, err)
+	}
+	return nil
+}
+
+/* Encrypts the secrets to a temporary file, replacing the secrets file and the secrets in memory once it's written */
+func (s *fileSecretStore) save(secrets map[string]string) error {
+	recipients, err := s.recipients()
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(secrets)
+	if err != nil {
+		return err
+	}
+
+	var encrypted bytes.Buffer
+	writer, err := age.Encrypt(&encrypted, recipients...)
+	if err != nil {
+		return err
+	}
+	if _, err := writer.Write(data); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return err
+	}
+	tempFile, err := os.CreateTemp(filepath.Dir(s.path), ".secrets-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tempFile.Name())
+	if _, err := tempFile.Write(encrypted.Bytes()); err != nil {
+		tempFile.Close()
+		return err
+	}
+	if err := tempFile.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tempFile.Name(), s.path); err != nil {
+		return err
+	}
+	s.secrets = secrets
+	return nil
+}
+
+func (s *fileSecretStore) identities() ([]age.Identity, error) {
+	if identityFile := os.Getenv(SecretIdentityEnv); identityFile != "" {
+		return readIdentityFile(identityFile)
+	}
+	passphrase, err := s.readPassphrase(false)
+	if err != nil {
+		return nil, err
+	}
+	identity, err := age.NewScryptIdentity(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return []age.Identity{identity}, nil
+}
+
+func (s *fileSecretStore) recipients() ([]age.Recipient, error) {
+	if identityFile := os.Getenv(SecretIdentityEnv); identityFile != "" {
+		identities, err := readIdentityFile(identityFile)
+		if err != nil {
+			return nil, err
+		}
+		recipients := make([]age.Recipient, 0, len(identities))
+		for _, identity := range identities {
+			if x25519, ok := identity.(*age.X25519Identity); ok {
+				recipients = append(recipients, x25519.Recipient())
+			}
+		}
+		if len(recipients) == 0 {
+			return nil, fmt.Errorf("%s has no X25519 keys to encrypt the secrets with", identityFile)
+		}
+		return recipients, nil
+	}
+
+	// A new file is encrypted with a passphrase confirmed on the terminal
+	newFile := false
+	if _, err := os.Stat(s.path); os.IsNotExist(err) {
+		newFile = true
+	} else if err != nil {
+		return nil, fmt.Errorf("Error reading %s: %v", s.path, err)
+	}
+	passphrase, err := s.readPassphrase(newFile)
+	if err != nil {
+		return nil, err
+	}
+	recipient, err := age.NewScryptRecipient(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	recipient.SetWorkFactor(scryptWorkFactor)
+	return []age.Recipient{recipient}, nil
+}
+
+/* Returns the passphrase from the environment, otherwise asking for it on the terminal */
+func (s *fileSecretStore) readPassphrase(confirm bool) (string, error) {
+	if s.passphrase != "" {
+		return s.passphrase, nil
+	}
+	if passphrase := os.Getenv(SecretPassphraseEnv); passphrase != "" {
+		s.passphrase = passphrase
+		return passphrase, nil
+	}
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return "", fmt.Errorf("Set %s or %s to read the secrets in %s without a terminal", SecretPassphraseEnv, SecretIdentityEnv, s.path)
+	}
+
+	fmt.Fprintf(os.Stderr, "Passphrase for %s: ", s.path)
+	passphrase, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+	if len(passphrase) == 0 {
+		return "", fmt.Errorf("A passphrase is required for %s", s.path)
+	}
+	if confirm {
+		fmt.Fprint(os.Stderr, "Confirm the passphrase: ")
+		confirmation, err := term.ReadPassword(int(os.Stdin.Fd()))
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return "", err
+		}
+		if string(confirmation) != string(passphrase) {
+			return "", fmt.Errorf("The passphrases do not match")
+		}
+	}
+	s.passphrase = string(passphrase)
+	return s.passphrase, nil
+}
+
+func readIdentityFile(path string) ([]age.Identity, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("Error reading age identity file: %v", err)
+	}
+	identities, err := age.ParseIdentities(strings.NewReader(string(data)))
+	if err != nil {
+		return nil, fmt.Errorf("Error reading age identity file %s: %v", path, err)
+	}
+	return identities, nil
+}
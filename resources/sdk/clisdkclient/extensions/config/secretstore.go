@@ -0,0 +1,333 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/spf13/viper"
+	"github.com/zalando/go-keyring"
+)
+
+const (
+	// PlaintextSecretStore keeps secrets in the config file, as profiles without a secret store do
+	PlaintextSecretStore = "plaintext"
+	KeychainSecretStore  = "keychain"
+	FileSecretStore      = "file"
+	// Secret stores named helper:<name> run the credential helper gc-credential-<name>
+	helperSecretStorePrefix = "helper:"
+
+	// The value of a field kept in a secret store is a reference to the secret, e.g. secret-store:DEFAULT/client_secret
+	secretReferencePrefix = "secret-store:"
+	keychainService       = "gc"
+	// Shown in place of a secret that can't be read, so the profile can still be listed
+	unreadableSecret = "<unreadable>"
+)
+
+// SecretFields are the fields of a profile that are kept in the profile's secret store
+var SecretFields = []string{"client_secret", "access_token", "oauth_token_data", "proxy_password", "gateway_password"}
+
+// SecretStore keeps the secrets of profiles out of the config file. Keys are of the form <profile>/<field>
+type SecretStore interface {
+	Get(key string) (string, error)
+	Set(key string, value string) error
+	Delete(key string) error
+}
+
+// Stores are kept once opened so the encrypted file is only decrypted once
+var (
+	secretStores     = make(map[string]SecretStore)
+	secretStoresLock sync.Mutex
+)
+
+// NewSecretStore returns the secret store of the name: keychain, file, or helper:<name> for a credential helper
+func NewSecretStore(name string) (SecretStore, error) {
+	secretStoresLock.Lock()
+	defer secretStoresLock.Unlock()
+	if store, ok := secretStores[name]; ok {
+		return store, nil
+	}
+
+	var store SecretStore
+	switch {
+	case name == KeychainSecretStore:
+		store = newCachedSecretStore(&keychainSecretStore{})
+	case name == FileSecretStore:
+		store = &fileSecretStore{path: secretsFilePath()}
+	case strings.HasPrefix(name, helperSecretStorePrefix) && len(name) > len(helperSecretStorePrefix):
+		store = newCachedSecretStore(&helperSecretStore{helper: strings.TrimPrefix(name, helperSecretStorePrefix)})
+	default:
+		return nil, fmt.Errorf("Unknown secret store %q. Supported stores: %s, %s, %s, %s<name>", name, PlaintextSecretStore, KeychainSecretStore, FileSecretStore, helperSecretStorePrefix)
+	}
+	secretStores[name] = store
+	return store, nil
+}
+
+// SecretStoreName is the secret store the profile keeps its secrets in, or plaintext if they're kept in the config file
+func SecretStoreName(profileName string) string {
+	name := viper.GetString(fmt.Sprintf("%s.secret_store", profileName))
+	if name == "" {
+		return PlaintextSecretStore
+	}
+	return name
+}
+
+// SetSecretStore sets the secret store secrets written to the profile are kept in. Secrets already written aren't moved, see MigrateSecrets
+func SetSecretStore(profileName string, storeName string) error {
+	if storeName != PlaintextSecretStore {
+		if _, err := NewSecretStore(storeName); err != nil {
+			return err
+		}
+	} else {
+		storeName = ""
+	}
+	viper.Set(fmt.Sprintf("%s.secret_store", profileName), storeName)
+	return nil
+}
+
+/*
+MigrateSecrets moves the secrets of the profile from the store they're kept in to the named store, writing references to them in
+the config file, or the secrets themselves when moving them to the plaintext store. The secrets are deleted from the previous store
+*/
+func MigrateSecrets(profileName string, storeName string) error {
+	previousStore := SecretStoreName(profileName)
+	secrets := make(map[string]string)
+	previousKeys := make([]string, 0)
+	for _, field := range SecretFields {
+		value, err := readSecret(profileName, field)
+		if err != nil {
+			return err
+		}
+		secrets[field] = value
+		if reference := viper.GetString(fmt.Sprintf("%s.%s", profileName, field)); strings.HasPrefix(reference, secretReferencePrefix) {
+			previousKeys = append(previousKeys, strings.TrimPrefix(reference, secretReferencePrefix))
+		}
+	}
+
+	if err := SetSecretStore(profileName, storeName); err != nil {
+		return err
+	}
+	for _, field := range SecretFields {
+		// Fields that aren't set are left out of the profile
+		if secrets[field] == "" && !viper.IsSet(fmt.Sprintf("%s.%s", profileName, field)) {
+			continue
+		}
+		// Clear the reference so the secret isn't deleted from the previous store before it's been written to the new one
+		viper.Set(fmt.Sprintf("%s.%s", profileName, field), "")
+		if err := writeSecret(profileName, field, secrets[field]); err != nil {
+			return err
+		}
+	}
+	if err := viper.WriteConfig(); err != nil {
+		return err
+	}
+
+	if previousStore != PlaintextSecretStore && previousStore != SecretStoreName(profileName) {
+		if store, err := NewSecretStore(previousStore); err == nil {
+			for _, key := range previousKeys {
+				_ = store.Delete(key)
+			}
+		}
+	}
+	return nil
+}
+
+func secretKey(profileName string, field string) string {
+	return fmt.Sprintf("%s/%s", profileName, field)
+}
+
+/* Returns the field's value, reading it from the secret store if the config file holds a reference to it */
+func readSecret(profileName string, field string) (string, error) {
+	value := viper.GetString(fmt.Sprintf("%s.%s", profileName, field))
+	if !strings.HasPrefix(value, secretReferencePrefix) {
+		return value, nil
+	}
+
+	store, err := NewSecretStore(SecretStoreName(profileName))
+	if err != nil {
+		return "", err
+	}
+	secret, err := store.Get(strings.TrimPrefix(value, secretReferencePrefix))
+	if err != nil {
+		return "", fmt.Errorf("Error reading %s of profile %s from the %s secret store: %v", field, profileName, SecretStoreName(profileName), err)
+	}
+	return secret, nil
+}
+
+/* Like readSecret for the configuration getters, which don't return errors. GetConfig checks the secrets can be read, so a placeholder is only returned for profiles that are listed */
+func getSecret(profileName string, field string) string {
+	secret, err := readSecret(profileName, field)
+	if err != nil {
+		return unreadableSecret
+	}
+	return secret
+}
+
+/* Returns an error for the first of the profile's secrets that can't be read */
+func checkSecrets(profileName string) error {
+	for _, field := range SecretFields {
+		if _, err := readSecret(profileName, field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/* Sets the field, keeping the value in the profile's secret store and a reference to it in the config file if the profile has one */
+func writeSecret(profileName string, field string, value string) error {
+	key := fmt.Sprintf("%s.%s", profileName, field)
+	storeName := SecretStoreName(profileName)
+	if storeName == PlaintextSecretStore {
+		viper.Set(key, value)
+		return nil
+	}
+
+	store, err := NewSecretStore(storeName)
+	if err != nil {
+		return err
+	}
+	if value == "" {
+		if strings.HasPrefix(viper.GetString(key), secretReferencePrefix) {
+			_ = store.Delete(secretKey(profileName, field))
+		}
+		viper.Set(key, "")
+		return nil
+	}
+	if err := store.Set(secretKey(profileName, field), value); err != nil {
+		return fmt.Errorf("Error writing %s of profile %s to the %s secret store: %v", field, profileName, storeName, err)
+	}
+	viper.Set(key, secretReferencePrefix+secretKey(profileName, field))
+	return nil
+}
+
+/* The secrets file is kept next to the config file */
+func secretsFilePath() string {
+	if configFile := viper.ConfigFileUsed(); configFile != "" {
+		return filepath.Join(filepath.Dir(configFile), "secrets.age")
+	}
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, ".gc", "secrets.age")
+}
+
+/*
+cachedSecretStore keeps the secrets read from a store in memory, as the fields of a profile are read several times by each command
+and the store may be slow to read from, e.g. running a credential helper. A secret is dropped from the cache when it's set or deleted
+*/
+type cachedSecretStore struct {
+	store   SecretStore
+	lock    sync.Mutex
+	secrets map[string]string
+}
+
+func newCachedSecretStore(store SecretStore) *cachedSecretStore {
+	return &cachedSecretStore{store: store, secrets: make(map[string]string)}
+}
+
+func (s *cachedSecretStore) Get(key string) (string, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if secret, ok := s.secrets[key]; ok {
+		return secret, nil
+	}
+	secret, err := s.store.Get(key)
+	if err != nil {
+		return "", err
+	}
+	s.secrets[key] = secret
+	return secret, nil
+}
+
+func (s *cachedSecretStore) Set(key string, value string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	delete(s.secrets, key)
+	return s.store.Set(key, value)
+}
+
+func (s *cachedSecretStore) Delete(key string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	delete(s.secrets, key)
+	return s.store.Delete(key)
+}
+
+// keychainSecretStore keeps secrets in the OS keychain: the macOS Keychain, the Windows Credential Manager or the Secret Service on Linux
+type keychainSecretStore struct{}
+
+func (s *keychainSecretStore) Get(key string) (string, error) {
+	return keyring.Get(keychainService, key)
+}
+
+func (s *keychainSecretStore) Set(key string, value string) error {
+	return keyring.Set(keychainService, key, value)
+}
+
+func (s *keychainSecretStore) Delete(key string) error {
+	return keyring.Delete(keychainService, key)
+}
+
+/*
+helperSecretStore runs a credential helper, following the protocol of git's credential helpers. The helper is run with the action,
+get, store or erase, as its last argument and is given key=value lines on stdin: key, the key of the secret, and for store, secret.
+For get it writes secret=<value> to stdout. Helpers are named gc-credential-<name> and found on the PATH, unless given as a path
+*/
+type helperSecretStore struct {
+	helper string
+}
+
+func (s *helperSecretStore) Get(key string) (string, error) {
+	output, err := s.run("get", map[string]string{"key": key})
+	if err != nil {
+		return "", err
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		if name, value, found := strings.Cut(scanner.Text(), "="); found && name == "secret" {
+			return value, nil
+		}
+	}
+	return "", fmt.Errorf("the credential helper did not return a secret for %s", key)
+}
+
+func (s *helperSecretStore) Set(key string, value string) error {
+	_, err := s.run("store", map[string]string{"key": key, "secret": value})
+	return err
+}
+
+func (s *helperSecretStore) Delete(key string) error {
+	_, err := s.run("erase", map[string]string{"key": key})
+	return err
+}
+
+func (s *helperSecretStore) run(action string, attributes map[string]string) ([]byte, error) {
+	var input bytes.Buffer
+	for _, name := range []string{"key", "secret"} {
+		value, ok := attributes[name]
+		if !ok {
+			continue
+		}
+		if strings.ContainsAny(value, "\n\x00") {
+			return nil, fmt.Errorf("the %s can't be passed to a credential helper as it contains a newline", name)
+		}
+		fmt.Fprintf(&input, "%s=%s\n", name, value)
+	}
+	input.WriteString("\n")
+
+	args := strings.Fields(s.helper)
+	if !strings.ContainsAny(args[0], `/\`) {
+		args[0] = "gc-credential-" + args[0]
+	}
+	helperCmd := exec.Command(args[0], append(args[1:], action)...)
+	helperCmd.Stdin = &input
+	helperCmd.Stderr = os.Stderr
+	output, err := helperCmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("credential helper %s %s failed: %v", args[0], action, err)
+	}
+	return output, nil
+}
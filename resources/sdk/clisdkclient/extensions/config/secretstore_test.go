@@ -0,0 +1,237 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+	"github.com/spf13/viper"
+)
+
+/* Reads a config file holding the profile into a fresh viper, with no secret stores opened */
+func setupConfig(t *testing.T, profile string) string {
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "config.toml")
+	if err := os.WriteFile(configFile, []byte(profile), 0600); err != nil {
+		t.Fatal(err)
+	}
+	viper.Reset()
+	viper.SetConfigFile(configFile)
+	if err := viper.ReadInConfig(); err != nil {
+		t.Fatal(err)
+	}
+	secretStores = make(map[string]SecretStore)
+	scryptWorkFactor = 10
+	return dir
+}
+
+func TestFileSecretStore(t *testing.T) {
+	dir := setupConfig(t, "")
+	t.Setenv(SecretPassphraseEnv, "correct horse")
+	t.Setenv(SecretIdentityEnv, "")
+
+	store := &fileSecretStore{path: filepath.Join(dir, "secrets.age")}
+	if err := store.Set("DEFAULT/client_secret", "s3cret"); err != nil {
+		t.Fatalf("err should be nil, got: %v", err)
+	}
+	data, _ := os.ReadFile(store.path)
+	if strings.Contains(string(data), "s3cret") {
+		t.Error("Expected the secrets file to be encrypted")
+	}
+
+	// Reading the file again decrypts it with the passphrase
+	reopened := &fileSecretStore{path: store.path}
+	if secret, err := reopened.Get("DEFAULT/client_secret"); err != nil || secret != "s3cret" {
+		t.Errorf("Expected the secret to be read back, got %q, %v", secret, err)
+	}
+	if err := reopened.Delete("DEFAULT/client_secret"); err != nil {
+		t.Fatalf("err should be nil, got: %v", err)
+	}
+	if _, err := reopened.Get("DEFAULT/client_secret"); err == nil {
+		t.Error("Expected an error for a deleted secret")
+	}
+
+	t.Setenv(SecretPassphraseEnv, "wrong")
+	if _, err := (&fileSecretStore{path: store.path}).Get("DEFAULT/client_secret"); err == nil || !strings.Contains(err.Error(), "Error decrypting") {
+		t.Errorf("Expected an error decrypting with the wrong passphrase, got: %v", err)
+	}
+
+	// The file can be encrypted with the keys of an age identity file instead
+	identity, _ := age.GenerateX25519Identity()
+	identityFile := filepath.Join(dir, "key.txt")
+	os.WriteFile(identityFile, []byte("# created: today\n"+identity.String()+"\n"), 0600)
+	t.Setenv(SecretIdentityEnv, identityFile)
+	keyStore := &fileSecretStore{path: filepath.Join(dir, "keys.age")}
+	if err := keyStore.Set("DEFAULT/access_token", "token"); err != nil {
+		t.Fatalf("err should be nil, got: %v", err)
+	}
+	if secret, err := (&fileSecretStore{path: keyStore.path}).Get("DEFAULT/access_token"); err != nil || secret != "token" {
+		t.Errorf("Expected the secret to be read back with the identity, got %q, %v", secret, err)
+	}
+
+	// A file that can't be checked isn't taken for a new one
+	t.Setenv(SecretIdentityEnv, "")
+	if _, err := (&fileSecretStore{path: filepath.Join(identityFile, "secrets.age")}).recipients(); err == nil || !strings.Contains(err.Error(), "Error reading") {
+		t.Errorf("Expected an error checking the secrets file, got: %v", err)
+	}
+
+	// Changes that can't be saved aren't kept
+	unsaved := &fileSecretStore{path: filepath.Join(identityFile, "secrets.age"), secrets: map[string]string{"DEFAULT/client_secret": "s3cret"}}
+	if err := unsaved.Set("DEFAULT/access_token", "token"); err == nil {
+		t.Error("Expected an error saving the secret")
+	}
+	if err := unsaved.Delete("DEFAULT/client_secret"); err == nil {
+		t.Error("Expected an error saving the deleted secret")
+	}
+	if _, err := unsaved.Get("DEFAULT/access_token"); err == nil {
+		t.Error("Expected a secret that wasn't saved not to be read")
+	}
+	if secret, err := unsaved.Get("DEFAULT/client_secret"); err != nil || secret != "s3cret" {
+		t.Errorf("Expected a secret whose deletion wasn't saved to be kept, got %q, %v", secret, err)
+	}
+}
+
+func TestHelperSecretStore(t *testing.T) {
+	dir := setupConfig(t, "")
+	// A helper keeping each secret in a file named after its key
+	helper := filepath.Join(dir, "gc-credential-test")
+	script := `#!/bin/sh
+while IFS='=' read -r name value; do
+  [ -z "$name" ] && break
+  eval "$name=\"\$value\""
+done
+file="` + dir + `/$(echo "$key" | tr / _)"
+case "$1" in
+  get) echo "$key" >> "` + dir + `/gets"; [ -f "$file" ] && echo "secret=$(cat "$file")" ;;
+  store) printf '%s' "$secret" > "$file" ;;
+  erase) rm -f "$file" ;;
+esac
+`
+	if err := os.WriteFile(helper, []byte(script), 0700); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	store, err := NewSecretStore("helper:test")
+	if err != nil {
+		t.Fatalf("err should be nil, got: %v", err)
+	}
+	if err := store.Set("DEFAULT/client_secret", "s3cret"); err != nil {
+		t.Fatalf("err should be nil, got: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if secret, err := store.Get("DEFAULT/client_secret"); err != nil || secret != "s3cret" {
+			t.Errorf("Expected the secret from the helper, got %q, %v", secret, err)
+		}
+	}
+	// The secret is read from the cache after it's first read, and again once it's changed
+	if err := store.Set("DEFAULT/client_secret", "changed"); err != nil {
+		t.Fatalf("err should be nil, got: %v", err)
+	}
+	if secret, err := store.Get("DEFAULT/client_secret"); err != nil || secret != "changed" {
+		t.Errorf("Expected the changed secret from the helper, got %q, %v", secret, err)
+	}
+	if gets, _ := os.ReadFile(filepath.Join(dir, "gets")); strings.Count(string(gets), "\n") != 2 {
+		t.Errorf("Expected the helper to be run once for each value of the secret, got:\n%s", gets)
+	}
+	if err := store.Delete("DEFAULT/client_secret"); err != nil {
+		t.Fatalf("err should be nil, got: %v", err)
+	}
+	if _, err := store.Get("DEFAULT/client_secret"); err == nil {
+		t.Error("Expected an error for a secret the helper doesn't have")
+	}
+	if err := store.Set("DEFAULT/client_secret", "two\nlines"); err == nil {
+		t.Error("Expected an error for a secret with a newline")
+	}
+
+	// Helpers can be given by path
+	if _, err := (&helperSecretStore{helper: helper}).run("erase", map[string]string{"key": "k"}); err != nil {
+		t.Errorf("Expected the helper to be run from its path, got: %v", err)
+	}
+	if _, err := NewSecretStore("vault"); err == nil {
+		t.Error("Expected an error for an unknown secret store")
+	}
+}
+
+func TestMigrateSecrets(t *testing.T) {
+	dir := setupConfig(t, `[default]
+environment = "mypurecloud.com"
+client_credentials = "id"
+client_secret = "s3cret"
+oauth_token_data = '{"access_token": "token"}'
+`)
+	t.Setenv(SecretPassphraseEnv, "correct horse")
+	t.Setenv(SecretIdentityEnv, "")
+
+	if err := MigrateSecrets("default", FileSecretStore); err != nil {
+		t.Fatalf("err should be nil, got: %v", err)
+	}
+	data, _ := os.ReadFile(viper.ConfigFileUsed())
+	for _, expected := range []string{`secret_store = 'file'`, `client_secret = 'secret-store:default/client_secret'`, `oauth_token_data = 'secret-store:default/oauth_token_data'`} {
+		if !strings.Contains(string(data), expected) {
+			t.Errorf("Expected the config file to contain %s, got:\n%s", expected, data)
+		}
+	}
+	if strings.Contains(string(data), "s3cret") {
+		t.Errorf("Expected the secret to be moved out of the config file, got:\n%s", data)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "secrets.age")); err != nil {
+		t.Errorf("Expected the secrets file next to the config file, got: %v", err)
+	}
+
+	// The configuration reads the secrets from the store
+	c, err := GetConfig("default")
+	if err != nil {
+		t.Fatalf("err should be nil, got: %v", err)
+	}
+	if c.ClientSecret() != "s3cret" || c.OAuthTokenData() != `{"access_token": "token"}` {
+		t.Errorf("Expected the secrets to be read from the store, got %q and %q", c.ClientSecret(), c.OAuthTokenData())
+	}
+
+	// Secrets written to the profile are kept in its store
+	if err := updateConfig(configuration{profileName: "default", accessToken: "new-token"}, nil, nil, nil); err != nil {
+		t.Fatalf("err should be nil, got: %v", err)
+	}
+	if viper.GetString("default.access_token") != "secret-store:default/access_token" || c.AccessToken() != "new-token" {
+		t.Errorf("Expected the access token to be kept in the store, got %q", viper.GetString("default.access_token"))
+	}
+
+	// Moving the secrets back to the config file
+	if err := MigrateSecrets("default", PlaintextSecretStore); err != nil {
+		t.Fatalf("err should be nil, got: %v", err)
+	}
+	data, _ = os.ReadFile(viper.ConfigFileUsed())
+	if !strings.Contains(string(data), `client_secret = 's3cret'`) || !strings.Contains(string(data), `access_token = 'new-token'`) {
+		t.Errorf("Expected the secrets to be written to the config file, got:\n%s", data)
+	}
+	store := &fileSecretStore{path: filepath.Join(dir, "secrets.age")}
+	if _, err := store.Get("default/client_secret"); err == nil {
+		t.Error("Expected the secrets to be deleted from the previous store")
+	}
+}
+
+func TestUnreadableSecret(t *testing.T) {
+	setupConfig(t, `[default]
+environment = "mypurecloud.com"
+client_credentials = "id"
+client_secret = "secret-store:default/client_secret"
+secret_store = "file"
+`)
+	t.Setenv(SecretPassphraseEnv, "correct horse")
+	t.Setenv(SecretIdentityEnv, "")
+
+	if _, err := GetConfig("default"); err == nil || !strings.Contains(err.Error(), "Error reading client_secret of profile default") {
+		t.Errorf("Expected an error for a secret missing from the store, got: %v", err)
+	}
+
+	// Profiles are still listed, with a placeholder for the secret
+	configs, err := ListConfigs()
+	if err != nil {
+		t.Fatalf("err should be nil, got: %v", err)
+	}
+	if len(configs) != 1 || !strings.Contains(configs[0].String(), `"clientSecret": "<unreadable>"`) {
+		t.Errorf("Expected the secret to be shown as unreadable, got: %v", configs)
+	}
+}
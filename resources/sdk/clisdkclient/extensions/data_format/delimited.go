@@ -0,0 +1,201 @@
+package data_format
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	CSV = "csv"
+	TSV = "tsv"
+	// Default separator placed between the elements of an array in a single cell
+	DefaultArraySeparator = ";"
+)
+
+var (
+	// Columns selected and ordered with --columns. All the fields of the records are output if empty
+	Columns []string
+	// Separator placed between the elements of arrays, set with --array-separator
+	ArraySeparator = DefaultArraySeparator
+
+	// The header is written once per run, so paginated output streamed page by page forms a single table.
+	// Shared by the delimited and table formats
+	headerColumns []string
+)
+
+// Record is a JSON object flattened to dotted column names, e.g. division.name
+type Record map[string]string
+
+// IsDelimited reports whether the output format is one of the delimited, spreadsheet friendly formats
+func IsDelimited(format string) bool {
+	return strings.EqualFold(format, CSV) || strings.EqualFold(format, TSV)
+}
+
+/*
+WriteDelimited writes the JSON data as CSV or TSV, with a row per object. Lists are written as a row per entity, whether
+they're an array or a page of results with an entities array. The header is taken from --columns, or from the fields of the
+first records written
+*/
+func WriteDelimited(w io.Writer, data string, format string) error {
+	records, columns, err := FlattenRecords(data)
+	if err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(w)
+	if strings.EqualFold(format, TSV) {
+		writer.Comma = '\t'
+	}
+	if headerColumns == nil {
+		// Wait for a page with records to take the columns from
+		if len(records) == 0 && len(Columns) == 0 {
+			return nil
+		}
+		headerColumns = columns
+		if len(Columns) > 0 {
+			headerColumns = Columns
+		}
+		if !NoHeaders {
+			if err := writer.Write(headerColumns); err != nil {
+				return err
+			}
+		}
+	}
+	for _, record := range records {
+		row := make([]string, len(headerColumns))
+		for i, column := range headerColumns {
+			row[i] = record[column]
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// FlattenRecords flattens each of the objects in the JSON data to a Record, and returns the columns found in the order they were first seen
+func FlattenRecords(data string) ([]Record, []string, error) {
+	var items []json.RawMessage
+	trimmed := bytes.TrimSpace([]byte(data))
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &items); err != nil {
+			return nil, nil, err
+		}
+	} else {
+		page := struct {
+			Entities []json.RawMessage `json:"entities"`
+		}{}
+		if err := json.Unmarshal(trimmed, &page); err != nil {
+			return nil, nil, err
+		}
+		items = []json.RawMessage{trimmed}
+		if page.Entities != nil {
+			items = page.Entities
+		}
+	}
+
+	records := make([]Record, 0, len(items))
+	columns := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, item := range items {
+		record := make(Record)
+		fields := make([]string, 0)
+		if err := flatten("", item, record, &fields); err != nil {
+			return nil, nil, err
+		}
+		for _, field := range fields {
+			if !seen[field] {
+				seen[field] = true
+				columns = append(columns, field)
+			}
+		}
+		records = append(records, record)
+	}
+	return records, columns, nil
+}
+
+/* Adds the leaves of the value to the record, keeping the order the fields appear in */
+func flatten(prefix string, value json.RawMessage, record Record, fields *[]string) error {
+	value = bytes.TrimSpace(value)
+	if len(value) == 0 || value[0] != '{' {
+		column := prefix
+		if column == "" {
+			column = "value"
+		}
+		cell, err := cellValue(value)
+		if err != nil {
+			return err
+		}
+		record[column] = cell
+		*fields = append(*fields, column)
+		return nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(value))
+	// Opening brace
+	if _, err := decoder.Token(); err != nil {
+		return err
+	}
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		var field json.RawMessage
+		if err := decoder.Decode(&field); err != nil {
+			return err
+		}
+		key := fmt.Sprintf("%v", token)
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		if err := flatten(key, field, record, fields); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/* Formats a scalar as it's written in the cell. The elements of arrays are joined, with any objects in them written as JSON */
+func cellValue(value json.RawMessage) (string, error) {
+	if len(value) == 0 {
+		return "", nil
+	}
+	switch value[0] {
+	case '"':
+		var s string
+		err := json.Unmarshal(value, &s)
+		return s, err
+	case '[':
+		var elements []json.RawMessage
+		if err := json.Unmarshal(value, &elements); err != nil {
+			return "", err
+		}
+		cells := make([]string, len(elements))
+		for i, element := range elements {
+			if element[0] == '{' || element[0] == '[' {
+				compacted := &bytes.Buffer{}
+				if err := json.Compact(compacted, element); err != nil {
+					return "", err
+				}
+				cells[i] = compacted.String()
+				continue
+			}
+			cell, err := cellValue(element)
+			if err != nil {
+				return "", err
+			}
+			cells[i] = cell
+		}
+		return strings.Join(cells, ArraySeparator), nil
+	case 'n':
+		return "", nil
+	default:
+		return string(value), nil
+	}
+}
@@ -0,0 +1,63 @@
+package data_format
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteDelimited(t *testing.T) {
+	page := `{
+		"entities": [
+			{"id": "1", "name": "Ann, A", "division": {"id": "d1", "name": "Home"}, "skills": ["a", "b"], "addresses": [{"address": "x"}]},
+			{"id": "2", "name": "Bob", "division": {"id": "d2", "name": "Away"}, "skills": [], "manager": null, "version": 3}
+		],
+		"pageSize": 25
+	}`
+
+	testCases := []struct {
+		format         string
+		columns        []string
+		arraySeparator string
+		data           string
+		expected       string
+	}{
+		{CSV, nil, DefaultArraySeparator, page, "id,name,division.id,division.name,skills,addresses,manager,version\n" +
+			`1,"Ann, A",d1,Home,a;b,"{""address"":""x""}",,` + "\n" +
+			"2,Bob,d2,Away,,,,3\n"},
+		{TSV, []string{"version", "division.name", "skills", "missing"}, "|", page, "version\tdivision.name\tskills\tmissing\n" +
+			"\tHome\ta|b\t\n" +
+			"3\tAway\t\t\n"},
+		{CSV, nil, DefaultArraySeparator, `[{"a": true, "b": 1.5}, {"c": "x"}]`, "a,b,c\ntrue,1.5,\n,,x\n"},
+		{CSV, nil, DefaultArraySeparator, `{"id": "1", "state": "active"}`, "id,state\n1,active\n"},
+		{CSV, nil, DefaultArraySeparator, `["a", "b"]`, "value\na\nb\n"},
+	}
+
+	for _, test := range testCases {
+		headerColumns = nil
+		Columns = test.columns
+		ArraySeparator = test.arraySeparator
+
+		output := &bytes.Buffer{}
+		if err := WriteDelimited(output, test.data, test.format); err != nil {
+			t.Fatalf("err should be nil, got: %v", err)
+		}
+		if output.String() != test.expected {
+			t.Errorf("Did not get the expected %v output.\nExpected:\n%s\nGot:\n%s", test.format, test.expected, output.String())
+		}
+	}
+
+	// Pages written one after the other form a single table
+	headerColumns = nil
+	Columns = nil
+	ArraySeparator = DefaultArraySeparator
+	output := &bytes.Buffer{}
+	WriteDelimited(output, `[{"id": "1", "name": "a"}]`, CSV)
+	WriteDelimited(output, `[{"name": "b", "id": "2", "extra": "x"}]`, CSV)
+	if output.String() != "id,name\n1,a\n2,b\n" {
+		t.Errorf("Expected the header to be written once, got:\n%s", output.String())
+	}
+
+	if err := WriteDelimited(output, `[1, 2`, CSV); err == nil {
+		t.Error("Expected an error for invalid JSON")
+	}
+}
@@ -0,0 +1,271 @@
+package data_format
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	yamlv3 "go.yaml.in/yaml/v3"
+	"sigs.k8s.io/yaml"
+)
+
+const YAML = "yaml"
+
+var timeType = reflect.TypeOf(time.Time{})
+
+/*
+ToJSONDocuments converts request body input in the input format to JSON, returning a document for each request it holds:
+  - CSV has a request per row, with the header row naming the path of each column's field, e.g. division.id or addresses.0.address
+  - YAML can hold several documents separated by ---
+  - JSON can hold several values one after another, e.g. NDJSON
+
+A single JSON array or YAML sequence is one request body, as it's the body of operations that take a list. CSV values are given
+the types of the fields of bodyModel, the model of the request body, and are otherwise strings
+*/
+func ToJSONDocuments(data string, bodyModel interface{}) ([]string, error) {
+	bodyType := reflect.TypeOf(bodyModel)
+	for bodyType != nil && bodyType.Kind() == reflect.Ptr {
+		bodyType = bodyType.Elem()
+	}
+
+	switch {
+	case strings.EqualFold(InputFormat, CSV):
+		return csvDocuments(data, bodyType)
+	case strings.EqualFold(InputFormat, YAML):
+		return yamlDocuments(data)
+	default:
+		return jsonDocuments(data), nil
+	}
+}
+
+/* Returns each of the JSON values in the data. Data that isn't valid JSON is returned as it is, for the API to report */
+func jsonDocuments(data string) []string {
+	decoder := json.NewDecoder(strings.NewReader(data))
+	documents := make([]string, 0)
+	for {
+		var document json.RawMessage
+		err := decoder.Decode(&document)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return []string{data}
+		}
+		documents = append(documents, string(document))
+	}
+	if len(documents) == 0 {
+		return []string{data}
+	}
+	return documents
+}
+
+/* Converts each of the documents in the YAML. Single documents are converted as they were before multiple documents were supported */
+func yamlDocuments(data string) ([]string, error) {
+	decoder := yamlv3.NewDecoder(strings.NewReader(data))
+	nodes := make([]*yamlv3.Node, 0)
+	for {
+		node := &yamlv3.Node{}
+		err := decoder.Decode(node)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Error converting YAML to JSON: %v", err)
+		}
+		nodes = append(nodes, node)
+	}
+	if len(nodes) <= 1 {
+		result, err := yaml.YAMLToJSON([]byte(data))
+		if err != nil {
+			return nil, fmt.Errorf("Error converting YAML to JSON: %v", err)
+		}
+		return []string{string(result)}, nil
+	}
+
+	documents := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		document, err := yamlv3.Marshal(node)
+		if err != nil {
+			return nil, err
+		}
+		result, err := yaml.YAMLToJSON(document)
+		if err != nil {
+			return nil, fmt.Errorf("Error converting YAML to JSON: %v", err)
+		}
+		documents = append(documents, string(result))
+	}
+	return documents, nil
+}
+
+/* Converts each row after the header to a JSON object. Empty cells are left out of the object */
+func csvDocuments(data string, bodyType reflect.Type) ([]string, error) {
+	rows, err := csv.NewReader(strings.NewReader(data)).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("Error reading CSV: %v", err)
+	}
+	if len(rows) < 2 {
+		return nil, fmt.Errorf("Error reading CSV: expected a header row followed by a row for each request")
+	}
+
+	header := rows[0]
+	documents := make([]string, 0, len(rows)-1)
+	for rowNumber, row := range rows[1:] {
+		var body interface{} = map[string]interface{}{}
+		for i, cell := range row {
+			if cell == "" {
+				continue
+			}
+			path := strings.Split(strings.TrimSpace(header[i]), ".")
+			value, err := csvValue(cell, fieldType(bodyType, path))
+			if err != nil {
+				return nil, fmt.Errorf("Error reading CSV row %d, column %s: %v", rowNumber+1, header[i], err)
+			}
+			if body, err = setPath(body, path, value); err != nil {
+				return nil, fmt.Errorf("Error reading CSV row %d, column %s: %v", rowNumber+1, header[i], err)
+			}
+		}
+		document, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		documents = append(documents, string(document))
+	}
+	return documents, nil
+}
+
+/* Finds the type of the field at the path of json names, or nil if the model doesn't have it */
+func fieldType(t reflect.Type, path []string) reflect.Type {
+	for _, key := range path {
+		for t != nil && t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t == nil {
+			return nil
+		}
+		switch t.Kind() {
+		case reflect.Struct:
+			var found reflect.Type
+			for i := 0; i < t.NumField(); i++ {
+				if name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]; name == key {
+					found = t.Field(i).Type
+					break
+				}
+			}
+			t = found
+		case reflect.Slice, reflect.Array:
+			if _, err := strconv.Atoi(key); err != nil {
+				return nil
+			}
+			t = t.Elem()
+		case reflect.Map:
+			t = t.Elem()
+		default:
+			return nil
+		}
+	}
+	return t
+}
+
+/* Converts the cell to the field's type. Lists of scalars are separated with the array separator, and objects are given as JSON */
+func csvValue(cell string, t reflect.Type) (interface{}, error) {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t == timeType {
+		return cell, nil
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return cell, nil
+	case reflect.Bool:
+		return strconv.ParseBool(cell)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if _, err := strconv.ParseInt(cell, 10, 64); err != nil {
+			return nil, fmt.Errorf("%q is not an integer", cell)
+		}
+		return json.Number(cell), nil
+	case reflect.Float32, reflect.Float64:
+		if _, err := strconv.ParseFloat(cell, 64); err != nil {
+			return nil, fmt.Errorf("%q is not a number", cell)
+		}
+		return json.Number(cell), nil
+	case reflect.Slice, reflect.Array:
+		if strings.HasPrefix(strings.TrimSpace(cell), "[") {
+			return jsonCell(cell)
+		}
+		elements := strings.Split(cell, ArraySeparator)
+		values := make([]interface{}, len(elements))
+		for i, element := range elements {
+			value, err := csvValue(element, t.Elem())
+			if err != nil {
+				return nil, err
+			}
+			values[i] = value
+		}
+		return values, nil
+	case reflect.Struct, reflect.Map:
+		return jsonCell(cell)
+	default:
+		if trimmed := strings.TrimSpace(cell); strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") {
+			return jsonCell(cell)
+		}
+		return cell, nil
+	}
+}
+
+func jsonCell(cell string) (interface{}, error) {
+	if !json.Valid([]byte(cell)) {
+		return nil, fmt.Errorf("%q is not valid JSON", cell)
+	}
+	return json.RawMessage(cell), nil
+}
+
+/* Sets the value at the path, creating the objects, and the lists for numeric keys, along it */
+func setPath(container interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	key := path[0]
+
+	if index, err := strconv.Atoi(key); err == nil {
+		list, ok := container.([]interface{})
+		if container == nil {
+			list, ok = []interface{}{}, true
+		}
+		if !ok {
+			return nil, fmt.Errorf("%v is a list index of a field that isn't a list", key)
+		}
+		if index < 0 || index > len(list) {
+			return nil, fmt.Errorf("list indexes must start at 0 and not skip any, got %v", key)
+		}
+		if index == len(list) {
+			list = append(list, nil)
+		}
+		element, err := setPath(list[index], path[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		list[index] = element
+		return list, nil
+	}
+
+	object, ok := container.(map[string]interface{})
+	if container == nil {
+		object, ok = map[string]interface{}{}, true
+	}
+	if !ok {
+		return nil, fmt.Errorf("%v is a field of a value that isn't an object", key)
+	}
+	field, err := setPath(object[key], path[1:], value)
+	if err != nil {
+		return nil, err
+	}
+	object[key] = field
+	return object, nil
+}
@@ -0,0 +1,101 @@
+package data_format
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testAddress struct {
+	Address *string `json:"address,omitempty"`
+	Primary *bool   `json:"primary,omitempty"`
+}
+
+type testDivision struct {
+	Id *string `json:"id,omitempty"`
+}
+
+type testUser struct {
+	Name      *string         `json:"name,omitempty"`
+	Age       *int            `json:"age,omitempty"`
+	Score     *float64        `json:"score,omitempty"`
+	Active    *bool           `json:"active,omitempty"`
+	Hired     *time.Time      `json:"hired,omitempty"`
+	Skills    *[]string       `json:"skills,omitempty"`
+	Ids       *[]int          `json:"ids,omitempty"`
+	Division  *testDivision   `json:"division,omitempty"`
+	Addresses *[]testAddress  `json:"addresses,omitempty"`
+	Settings  *map[string]int `json:"settings,omitempty"`
+}
+
+func TestToJSONDocuments(t *testing.T) {
+	defer func() {
+		InputFormat = ""
+		ArraySeparator = DefaultArraySeparator
+	}()
+
+	testCases := []struct {
+		inputFormat string
+		bodyModel   interface{}
+		data        string
+		expected    []string
+	}{
+		{CSV, testUser{}, "name,age,score,active,hired,skills,ids,division.id,addresses.0.address,addresses.0.primary,addresses.1.address,settings,extra.field\n" +
+			"Ann,30,1.5,true,2024-01-01T00:00:00Z,a;b,1;2,d1,1 Main St,false,2 Main St,\"{\"\"a\"\": 1}\",007\n" +
+			"\"Bob, B\",,,,,,,,,,,,\n", []string{
+			`{"active":true,"addresses":[{"address":"1 Main St","primary":false},{"address":"2 Main St"}],"age":30,"division":{"id":"d1"},"extra":{"field":"007"},"hired":"2024-01-01T00:00:00Z","ids":[1,2],"name":"Ann","score":1.5,"settings":{"a":1},"skills":["a","b"]}`,
+			`{"name":"Bob, B"}`,
+		}},
+		// Without a model values are strings
+		{CSV, nil, "name,age\nAnn,30\n", []string{`{"age":"30","name":"Ann"}`}},
+		{YAML, testUser{}, "name: a\n---\nname: b\nage: 2\n", []string{`{"name":"a"}`, `{"age":2,"name":"b"}`}},
+		{YAML, nil, "name: a\n", []string{`{"name":"a"}`}},
+		{"", testUser{}, "{\"name\": \"a\"}\n{\"name\": \"b\"}\n", []string{`{"name": "a"}`, `{"name": "b"}`}},
+		// A single list is one body, whether the operation takes a list or not
+		{YAML, []testUser{}, "- name: a\n- name: b\n", []string{`[{"name":"a"},{"name":"b"}]`}},
+		{YAML, testUser{}, "- name: a\n- name: b\n", []string{`[{"name":"a"},{"name":"b"}]`}},
+		{"", []testUser{}, `[{"name": "a"}, {"name": "b"}]`, []string{`[{"name": "a"}, {"name": "b"}]`}},
+		{"", testUser{}, `[{"name": "a"}, {"name": "b"}]`, []string{`[{"name": "a"}, {"name": "b"}]`}},
+		{"", nil, `[{"name": "a"}, {"name": "b"}]`, []string{`[{"name": "a"}, {"name": "b"}]`}},
+		// Several lists are a body each
+		{"", []testUser{}, "[{\"name\": \"a\"}]\n[{\"name\": \"b\"}]\n", []string{`[{"name": "a"}]`, `[{"name": "b"}]`}},
+		{"", testUser{}, `{"name": "a"`, []string{`{"name": "a"`}},
+	}
+
+	for _, test := range testCases {
+		InputFormat = test.inputFormat
+		documents, err := ToJSONDocuments(test.data, test.bodyModel)
+		if err != nil {
+			t.Fatalf("err should be nil for %q, got: %v", test.data, err)
+		}
+		if !reflect.DeepEqual(documents, test.expected) {
+			t.Errorf("Did not get the expected documents for %q.\nExpected: %v\nGot:      %v", test.data, test.expected, documents)
+		}
+	}
+
+	InputFormat = CSV
+	ArraySeparator = "|"
+	documents, _ := ToJSONDocuments("skills\na|b\n", testUser{})
+	if !reflect.DeepEqual(documents, []string{`{"skills":["a","b"]}`}) {
+		t.Errorf("Expected the array separator to split lists, got: %v", documents)
+	}
+
+	errorCases := []struct {
+		data     string
+		expected string
+	}{
+		{"name,age\nAnn,thirty\n", `Error reading CSV row 1, column age: "thirty" is not an integer`},
+		{"name,active\nAnn,yes\n", `Error reading CSV row 1, column active`},
+		{"name,division\nAnn,d1\n", `Error reading CSV row 1, column division: "d1" is not valid JSON`},
+		{"addresses.1.address\nx\n", `list indexes must start at 0 and not skip any, got 1`},
+		{"name\n", `expected a header row followed by a row for each request`},
+		{"name,age\nAnn\n", `wrong number of fields`},
+	}
+	for _, test := range errorCases {
+		_, err := ToJSONDocuments(test.data, testUser{})
+		if err == nil || !strings.Contains(err.Error(), test.expected) {
+			t.Errorf("Expected an error containing %q for %q, got: %v", test.expected, test.data, err)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package data_format
+
+import (
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+)
+
+const (
+	Table = "table"
+	// Space left between the columns of a table
+	tableColumnGap = 2
+	// Columns aren't truncated to narrower than this to fit the terminal
+	minTableColumnWidth = 6
+)
+
+var (
+	// Set with --no-headers to leave out the header row of table, CSV and TSV output
+	NoHeaders bool
+
+	// Columns shown by default in table output, in this order, if the records have them. Most resources have some of these fields
+	DefaultTableColumns = []string{"id", "name", "state", "status", "type", "email", "username", "division.name", "version", "dateCreated", "dateModified", "selfUri"}
+
+	// Widths of the table's columns, fixed by the first records written so streamed pages line up
+	tableWidths []int
+)
+
+// IsTable reports whether the output format is the aligned table format
+func IsTable(format string) bool {
+	return strings.EqualFold(format, Table)
+}
+
+/*
+WriteTable writes the JSON data as a table with a row per object and the columns aligned. The columns are taken from --columns,
+or are the DefaultTableColumns the first records have, and if they have none of them all their fields.
+Cells are truncated so rows fit within width if it's greater than zero
+*/
+func WriteTable(w io.Writer, data string, width int) error {
+	records, columns, err := FlattenRecords(data)
+	if err != nil {
+		return err
+	}
+
+	if headerColumns == nil {
+		if len(records) == 0 && len(Columns) == 0 {
+			return nil
+		}
+		headerColumns = Columns
+		if len(headerColumns) == 0 {
+			headerColumns = defaultTableColumns(columns)
+		}
+		tableWidths = columnWidths(headerColumns, records)
+		fitColumnWidths(tableWidths, width)
+		if !NoHeaders {
+			writeTableRow(w, headerColumns)
+		}
+	}
+
+	for _, record := range records {
+		row := make([]string, len(headerColumns))
+		for i, column := range headerColumns {
+			row[i] = record[column]
+		}
+		writeTableRow(w, row)
+	}
+	return nil
+}
+
+// TerminalWidth returns the width of the terminal output is written to, or zero if the output is redirected
+func TerminalWidth() int {
+	if !term.IsTerminal(int(os.Stdout.Fd())) {
+		return 0
+	}
+	width, _, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil {
+		return 0
+	}
+	return width
+}
+
+func defaultTableColumns(columns []string) []string {
+	present := make(map[string]bool)
+	for _, column := range columns {
+		present[column] = true
+	}
+	defaults := make([]string, 0)
+	for _, column := range DefaultTableColumns {
+		if present[column] {
+			defaults = append(defaults, column)
+		}
+	}
+	if len(defaults) == 0 {
+		return columns
+	}
+	return defaults
+}
+
+func columnWidths(columns []string, records []Record) []int {
+	widths := make([]int, len(columns))
+	for i, column := range columns {
+		if !NoHeaders {
+			widths[i] = utf8.RuneCountInString(column)
+		}
+		for _, record := range records {
+			if cellWidth := utf8.RuneCountInString(tableCell(record[column])); cellWidth > widths[i] {
+				widths[i] = cellWidth
+			}
+		}
+	}
+	return widths
+}
+
+/* Narrows the widest columns until the row fits the width, leaving every column at least minTableColumnWidth wide */
+func fitColumnWidths(widths []int, width int) {
+	if width <= 0 {
+		return
+	}
+	total := tableColumnGap * (len(widths) - 1)
+	for _, w := range widths {
+		total += w
+	}
+	for total > width {
+		widest := 0
+		for i := range widths {
+			if widths[i] > widths[widest] {
+				widest = i
+			}
+		}
+		if widths[widest] <= minTableColumnWidth {
+			return
+		}
+		widths[widest]--
+		total--
+	}
+}
+
+func writeTableRow(w io.Writer, cells []string) {
+	row := &strings.Builder{}
+	for i, cell := range cells {
+		cell = truncateCell(tableCell(cell), tableWidths[i])
+		row.WriteString(cell)
+		if i < len(cells)-1 {
+			row.WriteString(strings.Repeat(" ", tableWidths[i]-utf8.RuneCountInString(cell)+tableColumnGap))
+		}
+	}
+	io.WriteString(w, strings.TrimRight(row.String(), " ")+"\n")
+}
+
+/* Keeps each cell on one line */
+func tableCell(cell string) string {
+	return strings.Join(strings.Fields(cell), " ")
+}
+
+func truncateCell(cell string, width int) string {
+	if utf8.RuneCountInString(cell) <= width {
+		return cell
+	}
+	// Columns that were empty on the first page have no room for later values
+	if width < 1 {
+		return ""
+	}
+	runes := []rune(cell)
+	return string(runes[:width-1]) + "…"
+}
@@ -0,0 +1,68 @@
+package data_format
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteTable(t *testing.T) {
+	page := `{
+		"entities": [
+			{"id": "1", "name": "Ann Able", "division": {"name": "Home"}, "state": "active", "chat": {"jabberId": "a@x"}},
+			{"id": "22", "name": "Bob", "division": {"name": "Away"}, "state": "inactive", "chat": {"jabberId": "b@x"}}
+		]
+	}`
+
+	testCases := []struct {
+		columns   []string
+		noHeaders bool
+		width     int
+		data      string
+		expected  string
+	}{
+		{nil, false, 0, page, "" +
+			"id  name      state     division.name\n" +
+			"1   Ann Able  active    Home\n" +
+			"22  Bob       inactive  Away\n"},
+		{[]string{"chat.jabberId", "name"}, true, 0, page, "" +
+			"a@x  Ann Able\n" +
+			"b@x  Bob\n"},
+		// The widest columns are truncated to fit the width
+		{[]string{"name", "state"}, false, 16, page, "" +
+			"name     state\n" +
+			"Ann Ab…  active\n" +
+			"Bob      inacti…\n"},
+		{nil, false, 0, `[{"key": "a", "value": "multi\nline"}]`, "" +
+			"key  value\n" +
+			"a    multi line\n"},
+		{nil, false, 0, `[]`, ""},
+	}
+
+	for _, test := range testCases {
+		headerColumns = nil
+		Columns = test.columns
+		NoHeaders = test.noHeaders
+
+		output := &bytes.Buffer{}
+		if err := WriteTable(output, test.data, test.width); err != nil {
+			t.Fatalf("err should be nil, got: %v", err)
+		}
+		if output.String() != test.expected {
+			t.Errorf("Did not get the expected table.\nExpected:\n%s\nGot:\n%s", test.expected, output.String())
+		}
+	}
+
+	// Streamed pages keep the columns and widths of the first page
+	headerColumns = nil
+	Columns = nil
+	NoHeaders = false
+	output := &bytes.Buffer{}
+	WriteTable(output, `[]`, 0)
+	WriteTable(output, `[{"id": "1", "name": "a"}]`, 0)
+	WriteTable(output, `[{"id": "22", "name": "b", "state": "active"}]`, 0)
+	WriteTable(output, `[{"id": "333", "name": "c"}]`, 0)
+	if output.String() != "id  name\n1   a\n22  b\n3…  c\n" {
+		t.Errorf("Expected streamed pages to form one table, got:\n%s", output.String())
+	}
+	NoHeaders = false
+}
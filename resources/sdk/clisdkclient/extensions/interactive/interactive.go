@@ -0,0 +1,265 @@
+package interactive
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mypurecloud/platform-client-sdk-cli/build/gc/models"
+)
+
+// Prompter asks for the values of a body's fields on in, writing the prompts to out
+type Prompter struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+func NewPrompter(in io.Reader, out io.Writer) *Prompter {
+	return &Prompter{in: bufio.NewReader(in), out: out}
+}
+
+// BuildBody prompts for each of the required fields of the schema, returning the body as indented JSON
+func (p *Prompter) BuildBody(schema *models.Schema) (string, error) {
+	body, err := p.promptObject("", schema)
+	if err != nil {
+		return "", err
+	}
+	result, err := json.MarshalIndent(body, "", "  ")
+	return string(result), err
+}
+
+func (p *Prompter) promptObject(path string, schema *models.Schema) (map[string]interface{}, error) {
+	object := make(map[string]interface{})
+	for _, name := range schema.Required {
+		property := schema.Properties[name]
+		if property == nil {
+			property = &models.Schema{}
+		}
+		value, err := p.promptValue(strings.TrimPrefix(path+"."+name, "."), property.Resolve())
+		if err != nil {
+			return nil, err
+		}
+		object[name] = value
+	}
+	return object, nil
+}
+
+/* Prompts until a valid value is given for the field */
+func (p *Prompter) promptValue(path string, schema *models.Schema) (interface{}, error) {
+	if schema.Type == "object" && len(schema.Properties) > 0 {
+		return p.promptObject(path, schema)
+	}
+
+	for {
+		fmt.Fprintf(p.out, "%s (%s): ", path, describe(schema))
+		answer, err := p.readLine()
+		if err != nil {
+			return nil, err
+		}
+		if answer == "" {
+			fmt.Fprintf(p.out, "%s is required\n", path)
+			continue
+		}
+		value, err := parse(schema, answer)
+		if err != nil {
+			fmt.Fprintf(p.out, "Invalid value for %s: %v\n", path, err)
+			continue
+		}
+		return value, nil
+	}
+}
+
+// Confirm asks a yes or no question, returning the default for an empty answer
+func (p *Prompter) Confirm(question string, defaultAnswer bool) (bool, error) {
+	options := "y/N"
+	if defaultAnswer {
+		options = "Y/n"
+	}
+	for {
+		fmt.Fprintf(p.out, "%s [%s]: ", question, options)
+		answer, err := p.readLine()
+		if err != nil {
+			return false, err
+		}
+		switch strings.ToLower(answer) {
+		case "":
+			return defaultAnswer, nil
+		case "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
+		}
+	}
+}
+
+func (p *Prompter) readLine() (string, error) {
+	line, err := p.in.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		if err == io.EOF {
+			return "", fmt.Errorf("no more input while building the request body")
+		}
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+func describe(s *models.Schema) string {
+	if len(s.Enum) > 0 {
+		choices := make([]string, len(s.Enum))
+		for i, choice := range s.Enum {
+			choices[i] = fmt.Sprintf("%d) %v", i+1, choice)
+		}
+		return "one of " + strings.Join(choices, ", ")
+	}
+	switch {
+	case s.Ref != "":
+		return fmt.Sprintf("%s as JSON", s.RefName())
+	case s.Type == "array":
+		if s.Items != nil && isScalar(s.Items.Resolve()) {
+			return fmt.Sprintf("comma separated list of %s", describe(s.Items.Resolve()))
+		}
+		return "array as JSON"
+	case s.Type == "object":
+		return "object as JSON"
+	case s.Format != "":
+		return fmt.Sprintf("%s, %s", s.Type, s.Format)
+	case s.Type != "":
+		return s.Type
+	}
+	return "JSON or string"
+}
+
+func isScalar(s *models.Schema) bool {
+	return s.Ref == "" && s.Type != "array" && s.Type != "object"
+}
+
+/* Converts the answer to the schema's type. Enum values can be chosen by their number */
+func parse(s *models.Schema, answer string) (interface{}, error) {
+	if len(s.Enum) > 0 {
+		if choice, err := strconv.Atoi(answer); err == nil && choice >= 1 && choice <= len(s.Enum) {
+			return s.Enum[choice-1], nil
+		}
+		for _, value := range s.Enum {
+			if fmt.Sprintf("%v", value) == answer {
+				return value, nil
+			}
+		}
+		return nil, fmt.Errorf("expected one of the choices")
+	}
+
+	switch {
+	case s.Ref != "" || s.Type == "object":
+		return parseJSON(answer, '{')
+	case s.Type == "array":
+		if strings.HasPrefix(answer, "[") || s.Items == nil || !isScalar(s.Items.Resolve()) {
+			return parseJSON(answer, '[')
+		}
+		values := make([]interface{}, 0)
+		for _, element := range strings.Split(answer, ",") {
+			value, err := parse(s.Items.Resolve(), strings.TrimSpace(element))
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+		return values, nil
+	case s.Type == "integer":
+		if _, err := strconv.ParseInt(answer, 10, 64); err != nil {
+			return nil, fmt.Errorf("expected an integer")
+		}
+		return json.Number(answer), nil
+	case s.Type == "number":
+		if _, err := strconv.ParseFloat(answer, 64); err != nil {
+			return nil, fmt.Errorf("expected a number")
+		}
+		return json.Number(answer), nil
+	case s.Type == "boolean":
+		value, err := strconv.ParseBool(answer)
+		if err != nil {
+			return nil, fmt.Errorf("expected true or false")
+		}
+		return value, nil
+	case s.Type == "":
+		if value, err := parseJSON(answer, 0); err == nil {
+			return value, nil
+		}
+	}
+	return answer, nil
+}
+
+/* Parses the answer as JSON, starting with the given character if it isn't zero */
+func parseJSON(answer string, start byte) (interface{}, error) {
+	if start != 0 && !strings.HasPrefix(answer, string(start)) {
+		return nil, fmt.Errorf("expected JSON starting with %c", start)
+	}
+	if !json.Valid([]byte(answer)) {
+		return nil, fmt.Errorf("invalid JSON")
+	}
+	return json.RawMessage(answer), nil
+}
+
+// EditBody opens the body in the user's editor, set with $VISUAL or $EDITOR, and returns it once the editor exits
+func EditBody(body string) (string, error) {
+	file, err := os.CreateTemp("", "gc-body-*.json")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(file.Name())
+	if _, err := file.WriteString(body); err != nil {
+		file.Close()
+		return "", err
+	}
+	file.Close()
+
+	editorArgs := strings.Fields(editor())
+	editorCmd := exec.Command(editorArgs[0], append(editorArgs[1:], file.Name())...)
+	editorCmd.Stdin, editorCmd.Stdout, editorCmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := editorCmd.Run(); err != nil {
+		return "", fmt.Errorf("Error running editor %s: %v", editorArgs[0], err)
+	}
+
+	edited, err := os.ReadFile(file.Name())
+	if err != nil {
+		return "", err
+	}
+	if !json.Valid(bytes.TrimSpace(edited)) {
+		return "", fmt.Errorf("The edited request body is not valid JSON")
+	}
+	return string(edited), nil
+}
+
+func editor() string {
+	for _, env := range []string{"VISUAL", "EDITOR"} {
+		if value := strings.TrimSpace(os.Getenv(env)); value != "" {
+			return value
+		}
+	}
+	if runtime.GOOS == "windows" {
+		return "notepad"
+	}
+	return "vi"
+}
+
+// OptionalFields lists the properties of the schema that weren't prompted for, to mention they can be added in the editor
+func OptionalFields(schema *models.Schema) []string {
+	required := make(map[string]bool)
+	for _, name := range schema.Required {
+		required[name] = true
+	}
+	optional := make([]string, 0)
+	for name, property := range schema.Properties {
+		if !required[name] && !property.ReadOnly {
+			optional = append(optional, name)
+		}
+	}
+	sort.Strings(optional)
+	return optional
+}
@@ -0,0 +1,123 @@
+package interactive
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mypurecloud/platform-client-sdk-cli/build/gc/models"
+)
+
+const testSchema = `{
+  "in": "body",
+  "name": "body",
+  "required": true,
+  "schema": {"$ref": "#/definitions/CreateUser"},
+  "x-genesys-body-schema": {
+    "type": "object",
+    "required": ["name", "state", "age", "active", "skills", "division", "address", "routingStatus"],
+    "properties": {
+      "name": {"type": "string"},
+      "state": {"type": "string", "enum": ["active", "inactive"]},
+      "age": {"type": "integer", "format": "int32"},
+      "active": {"type": "boolean"},
+      "skills": {"type": "array", "items": {"type": "string"}},
+      "division": {"$ref": "#/definitions/WritableDivision"},
+      "address": {"type": "object", "required": ["city"], "properties": {"city": {"type": "string"}, "zip": {"type": "string"}}},
+      "routingStatus": {"allOf": [{"$ref": "#/definitions/RoutingStatus"}]},
+      "title": {"type": "string"},
+      "id": {"readOnly": true},
+      "email": {"type": "string"}
+    }
+  }
+}`
+
+func TestBuildBody(t *testing.T) {
+	schema := models.ParseBodySchema(testSchema)
+	if schema == nil {
+		t.Fatal("Expected the body schema to be parsed")
+	}
+
+	answers := []string{
+		"",            // name is required
+		"Ann",         // name
+		"3",           // not one of the choices
+		"2",           // state, by its number
+		"thirty",      // not an integer
+		"30",          // age
+		"yes",         // not a boolean
+		"true",        // active
+		"a, b",        // skills
+		"d1",          // not JSON
+		`{"id":"d1"}`, // division
+		"Galway",      // address.city
+		`{"id":"r1"}`, // routingStatus
+	}
+	var out bytes.Buffer
+	body, err := NewPrompter(strings.NewReader(strings.Join(answers, "\n")+"\n"), &out).BuildBody(schema)
+	if err != nil {
+		t.Fatalf("err should be nil, got: %v", err)
+	}
+
+	var actual, expected interface{}
+	json.Unmarshal([]byte(body), &actual)
+	json.Unmarshal([]byte(`{"name":"Ann","state":"inactive","age":30,"active":true,"skills":["a","b"],"division":{"id":"d1"},"address":{"city":"Galway"},"routingStatus":{"id":"r1"}}`), &expected)
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Did not get the expected body.\nExpected: %v\nGot:      %v", expected, actual)
+	}
+
+	prompts := out.String()
+	for _, expectedPrompt := range []string{
+		"name is required",
+		"state (one of 1) active, 2) inactive): ",
+		"Invalid value for state: expected one of the choices",
+		"age (integer, int32): ",
+		"Invalid value for age: expected an integer",
+		"Invalid value for active: expected true or false",
+		"skills (comma separated list of string): ",
+		"division (WritableDivision as JSON): ",
+		"Invalid value for division: expected JSON starting with {",
+		"address.city (string): ",
+		"routingStatus (RoutingStatus as JSON): ",
+	} {
+		if !strings.Contains(prompts, expectedPrompt) {
+			t.Errorf("Expected the prompts to contain %q, got:\n%s", expectedPrompt, prompts)
+		}
+	}
+
+	if optional := OptionalFields(schema); !reflect.DeepEqual(optional, []string{"email", "title"}) {
+		t.Errorf("Expected the optional fields email and title, got: %v", optional)
+	}
+
+	// Running out of input before the required fields are given is an error
+	_, err = NewPrompter(strings.NewReader("Ann\n"), &out).BuildBody(schema)
+	if err == nil {
+		t.Error("Expected an error when the input ends before the body is complete")
+	}
+}
+
+func TestConfirm(t *testing.T) {
+	testCases := []struct {
+		input         string
+		defaultAnswer bool
+		expected      bool
+	}{
+		{"\n", true, true},
+		{"\n", false, false},
+		{"y\n", false, true},
+		{"maybe\nNo\n", true, false},
+		{"yes", false, true},
+	}
+	for _, test := range testCases {
+		var out bytes.Buffer
+		actual, err := NewPrompter(strings.NewReader(test.input), &out).Confirm("Send?", test.defaultAnswer)
+		if err != nil {
+			t.Fatalf("err should be nil for %q, got: %v", test.input, err)
+		}
+		if actual != test.expected {
+			t.Errorf("Expected %v for %q, got %v", test.expected, test.input, actual)
+		}
+	}
+}
@@ -14,6 +14,10 @@ type MockClientConfig struct {
 	ClientIDFunc              func() string
 	ClientSecretFunc          func() string
 	RedirectURIFunc           func() string
+	CredentialProcessFunc     func() string
+	OrgNameFunc               func() string
+	AssertionFileFunc         func() string
+	AssertionProcessFunc      func() string
 	OAuthTokenDataFunc        func() string
 	AccessTokenFunc           func() string
 	LogFilePathFunc           func() string
@@ -22,6 +26,7 @@ type MockClientConfig struct {
 	SecureLoginEnabledFunc    func() bool
 	ProxyConfigurationFunc    func() string
 	GateWayConfigurationFunc  func() string
+	RetryConfigurationFunc    func() string
 }
 
 var UpdatedAccessToken string
@@ -50,6 +55,22 @@ func (m *MockClientConfig) RedirectURI() string {
 	return m.RedirectURIFunc()
 }
 
+func (m *MockClientConfig) CredentialProcess() string {
+	return m.CredentialProcessFunc()
+}
+
+func (m *MockClientConfig) OrgName() string {
+	return m.OrgNameFunc()
+}
+
+func (m *MockClientConfig) AssertionFile() string {
+	return m.AssertionFileFunc()
+}
+
+func (m *MockClientConfig) AssertionProcess() string {
+	return m.AssertionProcessFunc()
+}
+
 func (m *MockClientConfig) OAuthTokenData() string {
 	return m.OAuthTokenDataFunc()
 }
@@ -82,6 +103,10 @@ func (m *MockClientConfig) GateWayConfiguration() string {
 	return m.GateWayConfigurationFunc()
 }
 
+func (m *MockClientConfig) RetryConfiguration() string {
+	return m.RetryConfigurationFunc()
+}
+
 func (m *MockClientConfig) String() string {
 	return fmt.Sprintf("\n-------------\nProfile Name: %s\nEnvironment: %s\nLogging Enabled: %v\nLog File Path: %s\nClient ID: %s\nClient Secret: %s\nRedirect URI: %s\nSecure Login Enabled: %v\nAccess Token: %s\nAutoPagination Enabled: %v\n--------------\n", m.ProfileName(), m.Environment(), m.LoggingEnabled(), m.LogFilePath(), m.ClientID(), m.ClientSecret(), m.RedirectURI(), m.SecureLoginEnabled(), m.AccessToken(), m.AutoPaginationEnabled())
 }
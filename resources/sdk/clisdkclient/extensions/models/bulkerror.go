@@ -0,0 +1,30 @@
+package models
+
+import (
+	"fmt"
+)
+
+// Exit codes when some of the requests of a bulk operation fail
+const (
+	ExitFailure        = 1
+	ExitPartialFailure = 2
+)
+
+// BulkError is returned when requests of a bulk operation failed. Results holds the outcome of every request
+type BulkError struct {
+	Results   string
+	Succeeded int
+	Failed    int
+}
+
+func (e BulkError) Error() string {
+	return fmt.Sprintf("%d requests failed, %d succeeded", e.Failed, e.Succeeded)
+}
+
+// ExitCode distinguishes a partial failure from every request failing
+func (e BulkError) ExitCode() int {
+	if e.Succeeded > 0 {
+		return ExitPartialFailure
+	}
+	return ExitFailure
+}
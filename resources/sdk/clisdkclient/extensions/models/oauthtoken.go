@@ -9,6 +9,8 @@ type OAuthToken struct {
 	TokenType   string `json:"token_type"`
 	ExpiresIn   int    `json:"expires_in"`
 	Error       string `json:"error"`
+	// RefreshToken is returned by the authorization code grants, and used to refresh the access token once it expires
+	RefreshToken string `json:"refresh_token,omitempty"`
 }
 
 type OAuthTokenData struct {
@@ -19,4 +21,4 @@ type OAuthTokenData struct {
 func (d OAuthTokenData) String() string {
 	bytes, _ := json.Marshal(d)
 	return string(bytes)
-}
\ No newline at end of file
+}
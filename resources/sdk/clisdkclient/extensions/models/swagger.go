@@ -1,6 +1,10 @@
 package models
 
-import "net/http"
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
 
 type Parameters struct {
 	Name        string `json:"name,omitempty"`
@@ -39,4 +43,54 @@ func (p Path) GetMethod(method string) *Method {
 	}
 
 	return nil
-}
\ No newline at end of file
+}
+
+// Schema is the part of a request body's JSON schema used to build and validate request bodies
+type Schema struct {
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Ref                  string             `json:"$ref,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+	ReadOnly             bool               `json:"readOnly,omitempty"`
+	// The definitions referred to by the body schema, set on the body schema only
+	Definitions map[string]*Schema `json:"definitions,omitempty"`
+}
+
+/*
+ParseBodySchema finds the schema of the request body in the body parameter's JSON. The generator attaches the body's definition, and
+the definitions it refers to, as x-genesys-body-schema, and otherwise the parameter's schema is used. Nil is returned if there's neither
+*/
+func ParseBodySchema(jsonSchema string) *Schema {
+	parameter := struct {
+		BodySchema *Schema `json:"x-genesys-body-schema"`
+		Schema     *Schema `json:"schema"`
+	}{}
+	if json.Unmarshal([]byte(jsonSchema), &parameter) != nil {
+		return nil
+	}
+	if parameter.BodySchema != nil {
+		return parameter.BodySchema
+	}
+	return parameter.Schema
+}
+
+// Resolve returns the schema a property refers to with allOf, which wraps the references of properties with descriptions
+func (s *Schema) Resolve() *Schema {
+	if len(s.AllOf) == 1 && s.Type == "" && len(s.Properties) == 0 {
+		return s.AllOf[0]
+	}
+	return s
+}
+
+// RefName returns the name of the definition the schema refers to, or an empty string
+func (s *Schema) RefName() string {
+	if s.Ref == "" {
+		return ""
+	}
+	return s.Ref[strings.LastIndex(s.Ref, "/")+1:]
+}
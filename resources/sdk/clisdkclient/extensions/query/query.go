@@ -0,0 +1,64 @@
+package query
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/itchyny/gojq"
+)
+
+// Expression is the jq expression applied to the output of commands
+var Expression string
+
+// Apply runs the jq expression on the JSON data, returning each value it outputs, as jq prints each on its own line. An expression
+// like `.[] | .name` returns a value per item, however many there are. Data that isn't JSON, such as the empty response of a delete,
+// is returned unchanged
+func Apply(data string, expression string) ([]string, error) {
+	query, err := gojq.Parse(expression)
+	if err != nil {
+		return nil, fmt.Errorf("invalid query %v: %v", expression, err)
+	}
+	code, err := gojq.Compile(query)
+	if err != nil {
+		return nil, fmt.Errorf("invalid query %v: %v", expression, err)
+	}
+
+	// Numbers are kept as they are written so large IDs don't lose precision
+	var input interface{}
+	decoder := json.NewDecoder(strings.NewReader(data))
+	decoder.UseNumber()
+	if strings.TrimSpace(data) == "" || decoder.Decode(&input) != nil || decoder.More() {
+		return []string{data}, nil
+	}
+
+	results := make([]string, 0)
+	iter := code.Run(input)
+	for {
+		value, ok := iter.Next()
+		if !ok {
+			break
+		}
+		if err, ok := value.(error); ok {
+			return nil, fmt.Errorf("error running query %v: %v", expression, err)
+		}
+		result, err := marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+/* Marshals the result without escaping characters such as < and & in strings */
+func marshal(value interface{}) (string, error) {
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(buffer.String(), "\n"), nil
+}
@@ -0,0 +1,54 @@
+package query
+
+import (
+	"reflect"
+	"testing"
+)
+
+const users = `{"entities": [
+	{"id": "1", "name": "Alice", "state": "active", "division": {"name": "Sales"}, "score": 7},
+	{"id": "2", "name": "Bob", "state": "inactive", "division": {"name": "Support"}, "score": 3},
+	{"id": "3", "name": "Carol", "state": "active", "division": {"name": "Support"}, "score": 5}
+], "pageSize": 25}`
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		expression string
+		data       string
+		expected   []string
+	}{
+		{".pageSize", users, []string{`25`}},
+		{".entities[0].division.name", users, []string{`"Sales"`}},
+		{".entities[] | .name", users, []string{`"Alice"`, `"Bob"`, `"Carol"`}},
+		{".entities[1:] | map(.id)", users, []string{`["2","3"]`}},
+		{`[.entities[] | select(.state == "active") | {name, division: .division.name}]`, users, []string{`[{"division":"Sales","name":"Alice"},{"division":"Support","name":"Carol"}]`}},
+		{".entities | sort_by(.score) | map(.name)", users, []string{`["Bob","Carol","Alice"]`}},
+		{".entities | map(.score) | add", users, []string{`15`}},
+		{".entities | group_by(.division.name) | map({(.[0].division.name): length}) | add", users, []string{`{"Sales":1,"Support":2}`}},
+		{`"<" + .name + ">"`, `{"name": "Alice"}`, []string{`"<Alice>"`}},
+		// The shape of the output doesn't depend on the number of items
+		{".[] | .id", `[{"id": "a"}, {"id": "b"}]`, []string{`"a"`, `"b"`}},
+		{".[] | .id", `[{"id": "a"}]`, []string{`"a"`}},
+		{".[] | .id", `[]`, []string{}},
+		{".id", `{"id": 12345678901234567890}`, []string{`12345678901234567890`}},
+		{".name", "", []string{""}},
+	}
+
+	for _, tc := range tests {
+		result, err := Apply(tc.data, tc.expression)
+		if err != nil {
+			t.Errorf("err should be nil for %v, got: %s", tc.expression, err)
+			continue
+		}
+		if !reflect.DeepEqual(result, tc.expected) {
+			t.Errorf("Did not get the expected result for %v, got: %q, want: %q", tc.expression, result, tc.expected)
+		}
+	}
+
+	if _, err := Apply(users, ".entities[] |"); err == nil {
+		t.Errorf("Expected an error for an invalid expression")
+	}
+	if _, err := Apply(users, ".pageSize | keys"); err == nil {
+		t.Errorf("Expected an error for an expression that fails on the data")
+	}
+}
@@ -0,0 +1,82 @@
+package restclient
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/mypurecloud/platform-client-sdk-cli/build/gc/config"
+	"github.com/mypurecloud/platform-client-sdk-cli/build/gc/models"
+	"github.com/tidwall/pretty"
+)
+
+const (
+	saml2BearerGrantType = "urn:ietf:params:oauth:grant-type:saml2-bearer"
+	jwtBearerGrantType   = "urn:ietf:params:oauth:grant-type:jwt-bearer"
+)
+
+/* Exchanges an assertion issued by the org's identity provider for a token, with the SAML2 bearer grant or the JWT bearer grant */
+func authorizeAssertionGrant(c config.Configuration) (models.OAuthTokenData, error) {
+	grantType := jwtBearerGrantType
+	if c.GrantType() == "5" {
+		grantType = saml2BearerGrantType
+	}
+	assertion, err := readAssertion(c, grantType)
+	if err != nil {
+		return models.OAuthTokenData{}, err
+	}
+
+	form := url.Values{}
+	form["grant_type"] = []string{grantType}
+	form["assertion"] = []string{assertion}
+	if c.OrgName() != "" {
+		form["orgName"] = []string{c.OrgName()}
+	}
+	statusCode, path, responseData, err := postLoginForm(c, "/oauth/token", form)
+	if err != nil {
+		return models.OAuthTokenData{}, err
+	}
+	if statusCode < http.StatusOK || statusCode >= http.StatusMultipleChoices {
+		return models.OAuthTokenData{}, models.HttpStatusError{Verb: http.MethodPost, Path: path, StatusCode: statusCode, Body: fmt.Sprintf("%s", pretty.Pretty(responseData))}
+	}
+
+	oAuthToken := &models.OAuthToken{}
+	if err := json.Unmarshal(responseData, oAuthToken); err != nil {
+		return models.OAuthTokenData{}, err
+	}
+	return createOAuthTokenResponse(c, *oAuthToken)
+}
+
+/* Reads the assertion from the profile's assertion file, or from the output of its assertion process */
+func readAssertion(c config.Configuration, grantType string) (string, error) {
+	var data []byte
+	var err error
+	switch {
+	case c.AssertionFile() != "":
+		data, err = os.ReadFile(c.AssertionFile())
+		if err != nil {
+			return "", fmt.Errorf("Error reading the assertion: %v", err)
+		}
+	case c.AssertionProcess() != "":
+		data, err = runCommand(c.AssertionProcess())
+		if err != nil {
+			return "", fmt.Errorf("Error running the assertion process %q: %v", c.AssertionProcess(), err)
+		}
+	default:
+		return "", fmt.Errorf("Set assertion_file or assertion_process in profile %s to read the assertion from", c.ProfileName())
+	}
+
+	assertion := strings.TrimSpace(string(data))
+	if assertion == "" {
+		return "", fmt.Errorf("The assertion of profile %s is empty", c.ProfileName())
+	}
+	// SAML2 assertions are sent base64url encoded, while identity providers often hand them out as XML
+	if grantType == saml2BearerGrantType && strings.HasPrefix(assertion, "<") {
+		assertion = base64.URLEncoding.EncodeToString([]byte(assertion))
+	}
+	return assertion, nil
+}
@@ -0,0 +1,88 @@
+package restclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/mypurecloud/platform-client-sdk-cli/build/gc/config"
+	"github.com/mypurecloud/platform-client-sdk-cli/build/gc/logger"
+	"github.com/mypurecloud/platform-client-sdk-cli/build/gc/models"
+)
+
+/*
+credentialProcessOutput is the JSON a profile's credential_process writes to stdout. The token expires after expires_in seconds, or at
+expiration, an RFC 3339 timestamp. A token without either isn't reused, so the process is run for each command
+*/
+type credentialProcessOutput struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int    `json:"expires_in"`
+	Expiration  string `json:"expiration"`
+}
+
+/* Runs the profile's credential process, caching the token it returns in the profile until it expires */
+func authorizeCredentialProcess(c config.Configuration) (models.OAuthTokenData, error) {
+	logger.Info("Running the credential process of profile ", c.ProfileName())
+	stdout, err := runCommand(c.CredentialProcess())
+	if err != nil {
+		return models.OAuthTokenData{}, fmt.Errorf("Error running the credential process %q: %v", c.CredentialProcess(), err)
+	}
+
+	output := &credentialProcessOutput{}
+	if err := json.Unmarshal(bytes.TrimSpace(stdout), output); err != nil {
+		return models.OAuthTokenData{}, fmt.Errorf("Error reading the output of the credential process %q: %v", c.CredentialProcess(), err)
+	}
+	if output.AccessToken == "" {
+		return models.OAuthTokenData{}, fmt.Errorf("The credential process %q did not return an access_token", c.CredentialProcess())
+	}
+
+	expiry := time.Now().Add(time.Duration(output.ExpiresIn) * time.Second)
+	if output.Expiration != "" {
+		var err error
+		expiry, err = time.Parse(time.RFC3339, output.Expiration)
+		if err != nil {
+			return models.OAuthTokenData{}, fmt.Errorf("Error reading the expiration returned by the credential process %q: %v", c.CredentialProcess(), err)
+		}
+		output.ExpiresIn = int(time.Until(expiry).Seconds())
+	}
+	if output.TokenType == "" {
+		output.TokenType = "bearer"
+	}
+
+	oAuthTokenData := &models.OAuthTokenData{
+		OAuthToken: models.OAuthToken{
+			AccessToken: output.AccessToken,
+			TokenType:   strings.ToLower(output.TokenType),
+			ExpiresIn:   output.ExpiresIn,
+		},
+		OAuthTokenExpiry: expiry.Format(time.RFC3339),
+	}
+	if !OverridesApplied() {
+		if err := UpdateOAuthToken(c, oAuthTokenData); err != nil {
+			return *oAuthTokenData, err
+		}
+	}
+	return *oAuthTokenData, nil
+}
+
+/* Runs the command with the shell, returning what it writes to stdout. It can prompt for input, for example for a second factor */
+func runCommand(command string) ([]byte, error) {
+	var processCmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		processCmd = exec.Command("cmd", "/C", command)
+	} else {
+		processCmd = exec.Command("sh", "-c", command)
+	}
+	processCmd.Stdin = os.Stdin
+	processCmd.Stderr = os.Stderr
+	var stdout bytes.Buffer
+	processCmd.Stdout = &stdout
+	err := processCmd.Run()
+	return stdout.Bytes(), err
+}
@@ -0,0 +1,77 @@
+package restclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/mypurecloud/platform-client-sdk-cli/build/gc/utils"
+	"sigs.k8s.io/yaml"
+)
+
+// DryRun is the format requests are printed in instead of being sent. Supported formats: json, yaml, curl
+var DryRun string
+
+const redactedToken = "Bearer <redacted>"
+
+type dryRunRequest struct {
+	Method  string            `json:"method"`
+	URL     string            `json:"url"`
+	Proxy   string            `json:"proxy,omitempty"`
+	Headers map[string]string `json:"headers"`
+	Body    json.RawMessage   `json:"body,omitempty"`
+}
+
+/* Describes the request in the given format with the bearer token and proxy password redacted */
+func formatDryRun(request *http.Request, data string, proxyURL *url.URL, format string) (string, error) {
+	dryRun := dryRunRequest{
+		Method:  request.Method,
+		URL:     request.URL.String(),
+		Headers: make(map[string]string),
+	}
+	if proxyURL != nil {
+		dryRun.Proxy = proxyURL.Redacted()
+	}
+	for name := range request.Header {
+		dryRun.Headers[name] = request.Header.Get(name)
+	}
+	if _, ok := dryRun.Headers["Authorization"]; ok {
+		dryRun.Headers["Authorization"] = redactedToken
+	}
+	if data != "" {
+		if json.Valid([]byte(data)) {
+			dryRun.Body = json.RawMessage(data)
+		} else {
+			dryRun.Body, _ = json.Marshal(data)
+		}
+	}
+
+	switch strings.ToLower(format) {
+	case "json":
+		jsonData, err := marshalDryRun(dryRun)
+		return strings.TrimSuffix(string(jsonData), "\n"), err
+	case "yaml":
+		jsonData, err := marshalDryRun(dryRun)
+		if err != nil {
+			return "", err
+		}
+		yamlData, err := yaml.JSONToYAML(jsonData)
+		return strings.TrimSuffix(string(yamlData), "\n"), err
+	case "curl":
+		return utils.FormatCurl(dryRun.Method, dryRun.URL, dryRun.Proxy, dryRun.Headers, data), nil
+	}
+	return "", fmt.Errorf("unsupported dry run format: %v. Supported formats: json, yaml, curl", format)
+}
+
+// marshalDryRun indents the request without escaping characters such as the brackets of the redacted token
+func marshalDryRun(dryRun dryRunRequest) ([]byte, error) {
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	err := encoder.Encode(dryRun)
+	return buffer.Bytes(), err
+}
@@ -1,7 +1,9 @@
 package retry
 
 import (
+	"fmt"
 	"net/http"
+	"sync"
 	"time"
 )
 
@@ -10,72 +12,89 @@ type RetryConfiguration struct {
 	RetryWaitMax   time.Duration  `json:"retry_wait_max,omitempty"`
 	RetryMax       int            `json:"retry_max,omitempty"`
 	RequestLogHook RequestLogHook `json:"request_log_hook,omitempty"`
+	// StatusRules overrides whether a response with the given status code is retried
+	StatusRules map[int]bool `json:"retry_status_rules,omitempty"`
+	// Throttle is shared by requests sent concurrently so they all back off when one of them is rate limited
+	Throttle *Throttle `json:"-"`
 }
 
-var retryConfiguration *RetryConfiguration
+// DefaultRetryConfiguration is the retry policy used when none has been configured
+func DefaultRetryConfiguration() *RetryConfiguration {
+	return &RetryConfiguration{
+		RetryWaitMin: 5 * time.Second,
+		RetryWaitMax: 60 * time.Second,
+		RetryMax:     20,
+	}
+}
+
+var (
+	retryConfiguration *RetryConfiguration
+	// Tracks the calls currently running so nested calls (e.g. fetching the first page of a listing) don't clear the configuration of the outer call
+	inFlight     int
+	inFlightLock sync.Mutex
+)
 
 func GetRetryConfiguration() *RetryConfiguration {
+	inFlightLock.Lock()
+	defer inFlightLock.Unlock()
 	return retryConfiguration
 }
 
+func acquire(retryConfig *RetryConfiguration) {
+	inFlightLock.Lock()
+	defer inFlightLock.Unlock()
+	inFlight++
+	retryConfiguration = retryConfig
+}
+
+func release() {
+	inFlightLock.Lock()
+	defer inFlightLock.Unlock()
+	if inFlight > 0 {
+		inFlight--
+	}
+	if inFlight == 0 {
+		retryConfiguration = nil
+	}
+}
+
 type RequestLogHook func(*http.Request, int)
 
+// RetryWithData sends a single request body. Bodies read from a directory are sent by the command service, which reports the outcome of each
 func RetryWithData(uri string, headerParams map[string]string, data []string, httpCall func(uri string, headerParams map[string]string, data string) (string, error)) func(retryConfig *RetryConfiguration) (string, error) {
 	return func(retryConfig *RetryConfiguration) (string, error) {
-		if retryConfig == nil {
-			retryConfig = &RetryConfiguration{
-				RetryWaitMin: 5 * time.Second,
-				RetryWaitMax: 60 * time.Second,
-				RetryMax:     20,
-			}
+		if len(data) != 1 {
+			return "", fmt.Errorf("expected a single request body, got %d", len(data))
 		}
-		retryConfiguration = retryConfig
 
-		// if there is only one item in the array (req body), then just return the response object
-		if len(data) == 1 {
-			res, err := httpCall(uri, headerParams, data[0])
-			return res, err
-		}
-
-		// if there is more than one item in the array (req bodies), the response will be an array of response objects
-		var response string = "["
-		for i, reqBody := range data {
-			res, err := httpCall(uri, headerParams, reqBody)
-			retryConfiguration = nil
-			if err != nil {
-				if i == len(data)-1 {
-					response += err.Error() + "]"
-				} else {
-					response += err.Error() + ","
-				}
-			} else {
-				if i == len(data)-1 {
-					response += res + "]"
-				} else {
-					response += res + ","
-				}
-			}
-		}
+		var response string
+		var err error
+		Do(retryConfig, func() {
+			response, err = httpCall(uri, headerParams, data[0])
+		})
 
-		// returning response and nil error as the error objects will be concatenated onto the response (if there are any)
-		return response, nil
+		return response, err
 	}
 }
 
 func Retry(uri string, headerParams map[string]string, httpCall func(uri string, headerParams map[string]string) (string, error)) func(retryConfig *RetryConfiguration) (string, error) {
 	return func(retryConfig *RetryConfiguration) (string, error) {
-		if retryConfig == nil {
-			retryConfig = &RetryConfiguration{
-				RetryWaitMin: 5 * time.Second,
-				RetryWaitMax: 60 * time.Second,
-				RetryMax:     20,
-			}
-		}
-		retryConfiguration = retryConfig
-
-		response, err := httpCall(uri, headerParams)
-		retryConfiguration = nil
+		var response string
+		var err error
+		Do(retryConfig, func() {
+			response, err = httpCall(uri, headerParams)
+		})
 
 		return response, err
 	}
 }
+
+// Do runs call with the retry configuration applied to the requests it makes
+func Do(retryConfig *RetryConfiguration, call func()) {
+	if retryConfig == nil {
+		retryConfig = DefaultRetryConfiguration()
+	}
+	acquire(retryConfig)
+	defer release()
+	call()
+}
@@ -0,0 +1,44 @@
+package retry
+
+import (
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Throttle holds back requests sent concurrently until the time the API asked clients to wait for
+type Throttle struct {
+	lock  sync.Mutex
+	until time.Time
+}
+
+// Wait blocks until requests may be sent again
+func (t *Throttle) Wait() {
+	for {
+		t.lock.Lock()
+		wait := time.Until(t.until)
+		t.lock.Unlock()
+		if wait <= 0 {
+			return
+		}
+		time.Sleep(wait)
+	}
+}
+
+// Pause holds back requests for the given duration, unless they are already held back for longer
+func (t *Throttle) Pause(duration time.Duration) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if until := time.Now().Add(duration); until.After(t.until) {
+		t.until = until
+	}
+}
+
+// RetryAfter converts the seconds in a Retry-After header to a duration. It's zero if the header isn't set or is invalid
+func RetryAfter(header string) time.Duration {
+	seconds, err := strconv.Atoi(header)
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
@@ -0,0 +1,39 @@
+package retry
+
+import (
+	"testing"
+	"time"
+)
+
+func TestThrottle(t *testing.T) {
+	throttle := &Throttle{}
+
+	start := time.Now()
+	throttle.Wait()
+	if waited := time.Since(start); waited > 10*time.Millisecond {
+		t.Errorf("Expected Wait to return straight away, waited %v", waited)
+	}
+
+	throttle.Pause(50 * time.Millisecond)
+	// A shorter pause doesn't cut the longer one short
+	throttle.Pause(time.Millisecond)
+	start = time.Now()
+	throttle.Wait()
+	if waited := time.Since(start); waited < 40*time.Millisecond {
+		t.Errorf("Expected Wait to block until the pause ends, waited %v", waited)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	tests := map[string]time.Duration{
+		"":     0,
+		"3":    3 * time.Second,
+		"-1":   0,
+		"soon": 0,
+	}
+	for header, expected := range tests {
+		if retryAfter := RetryAfter(header); retryAfter != expected {
+			t.Errorf("Did not parse Retry-After: %q, got: %v, want: %v", header, retryAfter, expected)
+		}
+	}
+}
@@ -0,0 +1,372 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/mypurecloud/platform-client-sdk-cli/build/gc/config"
+	"github.com/mypurecloud/platform-client-sdk-cli/build/gc/models"
+	"github.com/mypurecloud/platform-client-sdk-cli/build/gc/restclient"
+	"github.com/mypurecloud/platform-client-sdk-cli/build/gc/retry"
+	"sigs.k8s.io/yaml"
+)
+
+// Annotations on generated commands naming the operation they call, used to resolve the commands listed in a batch manifest
+const (
+	MethodAnnotation = "method"
+	PathAnnotation   = "path"
+)
+
+// The outcome of each batch item
+const (
+	BatchSucceeded = "succeeded"
+	BatchFailed    = "failed"
+	BatchSkipped   = "skipped"
+)
+
+var pathParamRe = regexp.MustCompile(`\{[^}]+\}`)
+
+// BatchManifest lists the operations run by gc batch
+type BatchManifest struct {
+	// Number of items run at the same time. Defaults to 1
+	Concurrency int `json:"concurrency,omitempty"`
+	// Keep running the remaining items after an item fails
+	ContinueOnError bool        `json:"continueOnError,omitempty"`
+	Items           []BatchItem `json:"items"`
+}
+
+// BatchItem is an operation in a batch manifest, named either by its command (e.g. "users get") or by its method and path
+type BatchItem struct {
+	Name    string `json:"name,omitempty"`
+	Command string `json:"command,omitempty"`
+	Method  string `json:"method,omitempty"`
+	Path    string `json:"path,omitempty"`
+	// Values for the path parameters, in the order they appear in the path
+	Args    []string               `json:"args,omitempty"`
+	Query   map[string]interface{} `json:"query,omitempty"`
+	Headers map[string]string      `json:"headers,omitempty"`
+	Body    json.RawMessage        `json:"body,omitempty"`
+	// File the body is read from, relative to the manifest
+	File string `json:"file,omitempty"`
+	// Profile the operation is run with instead of the --profile one
+	Profile string `json:"profile,omitempty"`
+}
+
+// BatchResult is the outcome of a batch item
+type BatchResult struct {
+	Index         int             `json:"index"`
+	Name          string          `json:"name,omitempty"`
+	Method        string          `json:"method,omitempty"`
+	Path          string          `json:"path,omitempty"`
+	Profile       string          `json:"profile,omitempty"`
+	Status        string          `json:"status"`
+	StatusCode    int             `json:"statusCode,omitempty"`
+	CorrelationID string          `json:"correlationId,omitempty"`
+	Response      json.RawMessage `json:"response,omitempty"`
+	Error         json.RawMessage `json:"error,omitempty"`
+}
+
+// BatchReport summarises a batch run
+type BatchReport struct {
+	Succeeded int           `json:"succeeded"`
+	Failed    int           `json:"failed"`
+	Skipped   int           `json:"skipped"`
+	Results   []BatchResult `json:"results"`
+}
+
+// ResolveCommand returns the method and path of the operation called by a command
+type ResolveCommand func(command string) (method string, path string, err error)
+
+type batchRunner struct {
+	manifest       *BatchManifest
+	defaultProfile string
+	resolve        ResolveCommand
+
+	// One client is authorized per profile and shared by the items run with it. The lock only guards the map, so profiles are authorized concurrently
+	clientsLock sync.Mutex
+	clients     map[string]*batchClient
+	// Shared by the clients so every item backs off when one of them is rate limited
+	throttle *retry.Throttle
+
+	stopLock sync.Mutex
+	stopped  bool
+}
+
+type batchClient struct {
+	// Authorizes the client once, holding back only the items run with its profile
+	once        sync.Once
+	config      config.Configuration
+	restClient  *restclient.RESTClient
+	retryConfig *retry.RetryConfiguration
+	err         error
+}
+
+// LoadBatchManifest reads a YAML or JSON manifest, loading the bodies of the items that reference a file
+func LoadBatchManifest(fileName string) (*BatchManifest, error) {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the batch manifest %v: %v", fileName, err)
+	}
+
+	manifest := &BatchManifest{}
+	if err := yaml.Unmarshal(data, manifest); err != nil {
+		return nil, fmt.Errorf("invalid batch manifest %v: %v", fileName, err)
+	}
+	if len(manifest.Items) == 0 {
+		return nil, fmt.Errorf("the batch manifest %v has no items", fileName)
+	}
+
+	for i := range manifest.Items {
+		item := &manifest.Items[i]
+		if item.File == "" {
+			continue
+		}
+		if len(item.Body) > 0 {
+			return nil, fmt.Errorf("item %v sets both body and file", i)
+		}
+		bodyFile := item.File
+		if !filepath.IsAbs(bodyFile) {
+			bodyFile = filepath.Join(filepath.Dir(fileName), bodyFile)
+		}
+		body, err := os.ReadFile(bodyFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read the body of item %v: %v", i, err)
+		}
+		// YAML is a superset of JSON so both are accepted
+		item.Body, err = yaml.YAMLToJSON(body)
+		if err != nil {
+			return nil, fmt.Errorf("invalid body in %v: %v", bodyFile, err)
+		}
+	}
+	return manifest, nil
+}
+
+// RunBatch runs the items of the manifest, stopping at the first failure unless the manifest continues on error
+func RunBatch(manifest *BatchManifest, defaultProfile string, resolve ResolveCommand) *BatchReport {
+	runner := &batchRunner{
+		manifest:       manifest,
+		defaultProfile: defaultProfile,
+		resolve:        resolve,
+		clients:        make(map[string]*batchClient),
+		throttle:       &retry.Throttle{},
+	}
+
+	concurrency := manifest.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	results := make([]BatchResult, len(manifest.Items))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				// Items still queued once an item has failed are skipped
+				if runner.isStopped() {
+					results[i] = BatchResult{Index: i, Name: manifest.Items[i].Name, Status: BatchSkipped}
+					continue
+				}
+				results[i] = runner.run(i, manifest.Items[i])
+				if results[i].Status == BatchFailed && !manifest.ContinueOnError {
+					runner.stop()
+				}
+			}
+		}()
+	}
+	for i := range manifest.Items {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	report := &BatchReport{Results: results}
+	for _, result := range results {
+		switch result.Status {
+		case BatchSucceeded:
+			report.Succeeded++
+		case BatchFailed:
+			report.Failed++
+		case BatchSkipped:
+			report.Skipped++
+		}
+	}
+	return report
+}
+
+func (r *batchRunner) run(index int, item BatchItem) BatchResult {
+	result := BatchResult{Index: index, Name: item.Name, Profile: item.Profile}
+
+	method, uri, err := r.buildRequest(item)
+	if err != nil {
+		return failedBatchResult(result, err)
+	}
+	result.Method = method
+	result.Path = uri
+
+	profileName := item.Profile
+	if profileName == "" {
+		profileName = r.defaultProfile
+	}
+	client := r.client(profileName)
+	if client.err != nil {
+		return failedBatchResult(result, client.err)
+	}
+
+	headerParams := map[string]string{
+		"Content-Type": "application/json",
+		"Accept":       "application/json",
+	}
+	for name, value := range item.Headers {
+		headerParams[name] = value
+	}
+
+	// Items run with different profiles are sent concurrently, so the retry policy of the profile is passed with the request
+	response, err := callWithReAuthentication(client.config, client.restClient, method, uri, headerParams, batchBody(item.Body), client.retryConfig)
+	if err != nil {
+		if httpErr, ok := err.(models.HttpStatusError); ok {
+			result.StatusCode = httpErr.StatusCode
+			result.CorrelationID = http.Header(httpErr.Headers).Get("Inin-Correlation-Id")
+		}
+		return failedBatchResult(result, err)
+	}
+
+	result.Status = BatchSucceeded
+	result.StatusCode = response.StatusCode
+	result.CorrelationID = response.CorrelationID
+	result.Response = rawJSON(response.Body)
+	return result
+}
+
+/* Resolves the method of the item and the URI with its path parameters and query filled in */
+func (r *batchRunner) buildRequest(item BatchItem) (string, string, error) {
+	method, path := strings.ToUpper(item.Method), item.Path
+	if item.Command != "" {
+		if method != "" || path != "" {
+			return "", "", fmt.Errorf("set either command or method and path")
+		}
+		var err error
+		method, path, err = r.resolve(item.Command)
+		if err != nil {
+			return "", "", err
+		}
+	}
+	if method == "" || path == "" {
+		return "", "", fmt.Errorf("a command or method and path is required")
+	}
+
+	params := pathParamRe.FindAllString(path, -1)
+	if len(params) != len(item.Args) {
+		return "", "", fmt.Errorf("%v %v expects %v args, got %v", method, path, len(params), len(item.Args))
+	}
+	for _, arg := range item.Args {
+		path = strings.Replace(path, pathParamRe.FindString(path), url.PathEscape(arg), 1)
+	}
+
+	if len(item.Query) == 0 {
+		return method, path, nil
+	}
+	query := url.Values{}
+	for name, value := range item.Query {
+		query.Set(name, queryValue(value))
+	}
+	return method, fmt.Sprintf("%v?%v", path, query.Encode()), nil
+}
+
+/* Returns the client for the profile, authorizing it the first time it's used */
+func (r *batchRunner) client(profileName string) *batchClient {
+	r.clientsLock.Lock()
+	client, ok := r.clients[profileName]
+	if !ok {
+		client = &batchClient{}
+		r.clients[profileName] = client
+	}
+	r.clientsLock.Unlock()
+
+	client.once.Do(func() {
+		c, err := configGetConfig(profileName)
+		if err != nil {
+			client.err = err
+			return
+		}
+		client.config = c
+		client.restClient, client.err = restclientNewProfileRESTClient(c)
+		client.retryConfig = newRetryConfiguration(c)
+		client.retryConfig.Throttle = r.throttle
+	})
+	return client
+}
+
+func (r *batchRunner) stop() {
+	r.stopLock.Lock()
+	defer r.stopLock.Unlock()
+	r.stopped = true
+}
+
+func (r *batchRunner) isStopped() bool {
+	r.stopLock.Lock()
+	defer r.stopLock.Unlock()
+	return r.stopped
+}
+
+func failedBatchResult(result BatchResult, err error) BatchResult {
+	result.Status = BatchFailed
+	result.Error = rawJSON(err.Error())
+	return result
+}
+
+/* A body given as a YAML string is sent as is, anything else is sent as JSON */
+func batchBody(body json.RawMessage) string {
+	if len(body) == 0 {
+		return ""
+	}
+	var text string
+	if err := json.Unmarshal(body, &text); err == nil {
+		return text
+	}
+	return string(body)
+}
+
+/* Lists are joined with commas, the way they are passed to the flags of generated commands */
+func queryValue(value interface{}) string {
+	if values, ok := value.([]interface{}); ok {
+		items := make([]string, 0, len(values))
+		for _, item := range values {
+			items = append(items, scalarQueryValue(item))
+		}
+		return strings.Join(items, ",")
+	}
+	return scalarQueryValue(value)
+}
+
+/* Numbers in the manifest are decoded as floats, which are written without an exponent so large values such as timestamps are sent as they were written */
+func scalarQueryValue(value interface{}) string {
+	if number, ok := value.(float64); ok {
+		return strconv.FormatFloat(number, 'f', -1, 64)
+	}
+	return fmt.Sprint(value)
+}
+
+/* Compacts JSON into the report and quotes anything else */
+func rawJSON(data string) json.RawMessage {
+	if strings.TrimSpace(data) == "" {
+		return nil
+	}
+	compacted := &bytes.Buffer{}
+	if err := json.Compact(compacted, []byte(data)); err == nil {
+		return compacted.Bytes()
+	}
+	quoted, _ := json.Marshal(data)
+	return quoted
+}
@@ -0,0 +1,203 @@
+package services
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/mypurecloud/platform-client-sdk-cli/build/gc/config"
+	"github.com/mypurecloud/platform-client-sdk-cli/build/gc/mocks"
+	"github.com/mypurecloud/platform-client-sdk-cli/build/gc/restclient"
+)
+
+func TestRunBatch(t *testing.T) {
+	configGetConfig = mockGetConfig
+	restclientNewProfileRESTClient = mockNewProfileRESTClient
+	setRestClientDoMockForBatch()
+
+	resolve := func(command string) (string, string, error) {
+		if command != "users get" {
+			return "", "", fmt.Errorf("unknown command: %v", command)
+		}
+		return http.MethodGet, "/api/v2/users/{userId}", nil
+	}
+	items := []BatchItem{
+		{Name: "get", Command: "users get", Args: []string{"abc"}, Query: map[string]interface{}{"expand": []interface{}{"skills", "groups"}, "after": float64(1700000000000)}},
+		{Name: "missing", Method: "get", Path: "/api/v2/missing"},
+		{Name: "create", Method: "POST", Path: "/api/v2/users", Body: []byte(`{"name":"Jane"}`), Profile: "other"},
+	}
+
+	report := RunBatch(&BatchManifest{Items: items, ContinueOnError: true}, "DEFAULT", resolve)
+	if report.Succeeded != 2 || report.Failed != 1 || report.Skipped != 0 {
+		t.Fatalf("Did not get the expected counts, got: %+v", report)
+	}
+	get, missing, create := report.Results[0], report.Results[1], report.Results[2]
+	if get.Path != "/api/v2/users/abc?after=1700000000000&expand=skills%2Cgroups" || get.StatusCode != http.StatusOK || get.CorrelationID != "corr-/api/v2/users/abc" || string(get.Response) != `{"id":"abc"}` {
+		t.Errorf("Did not get the expected result for the command item, got: %+v", get)
+	}
+	if missing.Status != BatchFailed || missing.Method != http.MethodGet || missing.StatusCode != http.StatusNotFound || missing.CorrelationID != "corr-/api/v2/missing" || string(missing.Error) != `{"message":"not found"}` {
+		t.Errorf("Did not get the expected result for the failing item, got: %+v", missing)
+	}
+	if create.Status != BatchSucceeded || create.Profile != "other" || string(create.Response) != `{"name":"Jane"}` {
+		t.Errorf("Did not get the expected result for the POST item, got: %+v", create)
+	}
+
+	report = RunBatch(&BatchManifest{Items: items}, "DEFAULT", resolve)
+	if report.Succeeded != 1 || report.Failed != 1 || report.Skipped != 1 || report.Results[2].Status != BatchSkipped {
+		t.Errorf("Did not stop at the first failure, got: %+v", report)
+	}
+
+	report = RunBatch(&BatchManifest{Items: []BatchItem{{Command: "users get"}, {Command: "users list", Args: []string{"abc"}}}, ContinueOnError: true}, "DEFAULT", resolve)
+	if report.Failed != 2 {
+		t.Errorf("Expected items with the wrong number of args or an unknown command to fail, got: %+v", report)
+	}
+}
+
+func TestRunBatchReAuthenticates(t *testing.T) {
+	restclient.OverridesApplied = mocks.OverridesApplied
+	restclient.UpdateOAuthToken = mocks.UpdateOAuthToken
+	configGetConfig = mockGetConfig
+	restclientNewProfileRESTClient = mockNewProfileRESTClient
+
+	// Requests are rejected until the client of their profile logs in
+	var lock sync.Mutex
+	logins := 0
+	restclient.ClientDo = func(client *retryablehttp.Client, request *retryablehttp.Request) (*http.Response, error) {
+		if request.URL.Path == "/oauth/token" {
+			lock.Lock()
+			logins++
+			lock.Unlock()
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(`{"access_token": "new-token", "token_type": "bearer", "expires_in": 3600}`))}, nil
+		}
+		if request.Header.Get("Authorization") != "Bearer new-token" {
+			return &http.Response{StatusCode: http.StatusUnauthorized, Body: io.NopCloser(strings.NewReader(""))}, nil
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(`{}`))}, nil
+	}
+
+	items := []BatchItem{
+		{Method: "GET", Path: "/api/v2/users/me"},
+		{Method: "GET", Path: "/api/v2/users/me", Profile: "other"},
+		{Method: "GET", Path: "/api/v2/users/me"},
+		{Method: "GET", Path: "/api/v2/users/me", Profile: "other"},
+	}
+	report := RunBatch(&BatchManifest{Items: items, Concurrency: 4}, "DEFAULT", nil)
+	if report.Succeeded != len(items) {
+		t.Fatalf("Expected every item to be sent again after re-authenticating, got: %+v", report)
+	}
+	if logins != 2 {
+		t.Errorf("Expected the client of each profile to re-authenticate once, got %v logins", logins)
+	}
+}
+
+func TestRunBatchAuthorizesProfilesConcurrently(t *testing.T) {
+	configGetConfig = mockGetConfig
+	setRestClientDoMockForBatch()
+	defer func() {
+		restclientNewProfileRESTClient = mockNewProfileRESTClient
+	}()
+
+	// The login of the slow profile only completes once the item run with the other profile has been sent
+	var once sync.Once
+	otherSent := make(chan struct{})
+	batchDo := restclient.ClientDo
+	restclient.ClientDo = func(client *retryablehttp.Client, request *retryablehttp.Request) (*http.Response, error) {
+		if request.URL.Path == "/api/v2/other" {
+			once.Do(func() { close(otherSent) })
+		}
+		return batchDo(client, request)
+	}
+	restclientNewProfileRESTClient = func(c config.Configuration) (*restclient.RESTClient, error) {
+		if c.ProfileName() == "slow" {
+			select {
+			case <-otherSent:
+			case <-time.After(time.Second):
+				return nil, fmt.Errorf("the login of the slow profile held back the other profile")
+			}
+		}
+		return mockNewProfileRESTClient(c)
+	}
+
+	items := []BatchItem{
+		{Method: "GET", Path: "/api/v2/slow", Profile: "slow"},
+		{Method: "GET", Path: "/api/v2/other", Profile: "other"},
+	}
+	report := RunBatch(&BatchManifest{Items: items, Concurrency: 2}, "DEFAULT", nil)
+	if report.Succeeded != len(items) {
+		t.Errorf("Expected each profile to be authorized without waiting for the others, got: %+v", report)
+	}
+}
+
+func TestLoadBatchManifest(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "user.yaml"), []byte("name: Jane\n"), 0600)
+	manifestFile := filepath.Join(dir, "manifest.yaml")
+	os.WriteFile(manifestFile, []byte(`concurrency: 4
+items:
+  - command: users create
+    file: user.yaml
+  - method: PATCH
+    path: /api/v2/users/{userId}
+    args: [abc]
+    query:
+      after: 1700000000000
+      ids: [12345678, 2.5]
+    body:
+      state: active
+`), 0600)
+
+	manifest, err := LoadBatchManifest(manifestFile)
+	if err != nil {
+		t.Fatalf("err should be nil, got: %s", err)
+	}
+	if manifest.Concurrency != 4 || len(manifest.Items) != 2 {
+		t.Fatalf("Did not load the manifest, got: %+v", manifest)
+	}
+	if string(manifest.Items[0].Body) != `{"name":"Jane"}` || string(manifest.Items[1].Body) != `{"state":"active"}` {
+		t.Errorf("Did not load the bodies as JSON, got: %s and %s", manifest.Items[0].Body, manifest.Items[1].Body)
+	}
+	query := manifest.Items[1].Query
+	if queryValue(query["after"]) != "1700000000000" || queryValue(query["ids"]) != "12345678,2.5" {
+		t.Errorf("Expected numbers in the query to be written as they are in the manifest, got: %v and %v", queryValue(query["after"]), queryValue(query["ids"]))
+	}
+}
+
+// setRestClientDoMockForBatch sets the restclient.ClientDo method to echo POST bodies, return the last path segment as the id of GETs and fail for /api/v2/missing
+func setRestClientDoMockForBatch() {
+	restclient.ClientDo = func(client *retryablehttp.Client, request *retryablehttp.Request) (*http.Response, error) {
+		header := http.Header{}
+		header.Set("Inin-Correlation-Id", "corr-"+request.URL.Path)
+		if request.URL.Path == "/api/v2/missing" {
+			return &http.Response{
+				StatusCode: http.StatusNotFound,
+				Header:     header,
+				Body:       io.NopCloser(strings.NewReader(`{"message":"not found"}`)),
+			}, nil
+		}
+
+		responseString := fmt.Sprintf(`{"id":"%v"}`, request.URL.Path[strings.LastIndex(request.URL.Path, "/")+1:])
+		if request.Method == http.MethodPost {
+			body, _ := io.ReadAll(request.Body)
+			responseString = string(body)
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     header,
+			Body:       io.NopCloser(strings.NewReader(responseString)),
+		}, nil
+	}
+}
+
+// mockNewProfileRESTClient returns a mock RESTClient object for the batch tests
+func mockNewProfileRESTClient(c config.Configuration) (*restclient.RESTClient, error) {
+	restClient := &restclient.RESTClient{}
+	restClient.SetConfig(c)
+	return restClient, nil
+}
@@ -0,0 +1,161 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/mypurecloud/platform-client-sdk-cli/build/gc/logger"
+	"github.com/mypurecloud/platform-client-sdk-cli/build/gc/models"
+	"github.com/mypurecloud/platform-client-sdk-cli/build/gc/retry"
+	"github.com/mypurecloud/platform-client-sdk-cli/build/gc/utils"
+	"github.com/mypurecloud/platform-client-sdk-cli/build/gc/validation"
+	"github.com/spf13/cobra"
+)
+
+// BulkResult is the outcome of sending one of the bodies of an upsert with several, from --directory or a file holding several bodies
+type BulkResult struct {
+	File          string          `json:"file"`
+	StatusCode    int             `json:"statusCode,omitempty"`
+	CorrelationID string          `json:"correlationId,omitempty"`
+	Response      json.RawMessage `json:"response,omitempty"`
+	Error         json.RawMessage `json:"error,omitempty"`
+	// Set for the files that weren't sent because a request failed with --fail-fast
+	Skipped bool `json:"skipped,omitempty"`
+}
+
+/* Sends the body built with --interactive, from --file or stdin, or each of the bodies in --directory or in a file holding several of them */
+func (c *commandService) upsert(cmd *cobra.Command, method string, uri string, headerParams map[string]string, httpCall func(uri string, headerParams map[string]string, data string) (string, error)) func(retryConfig *retry.RetryConfiguration) (string, error) {
+	flags := cmd.Flags()
+	if flags.Lookup("file") == nil || flags.Lookup("directory") == nil {
+		return retry.RetryWithData(uri, headerParams, []string{""}, httpCall)
+	}
+
+	if interactive, _ := flags.GetBool("interactive"); interactive {
+		body := utils.ReadInteractiveBody(cmd)
+		validateBodies(cmd, []utils.InputFile{{Name: "request body", Data: body}})
+		return retry.RetryWithData(uri, headerParams, []string{body}, httpCall)
+	}
+
+	// --file takes precedence over --directory
+	fileName, _ := flags.GetString("file")
+	dirName, _ := flags.GetString("directory")
+	files := utils.ReadInputFiles(cmd)
+	validateBodies(cmd, files)
+	if len(files) == 1 && (fileName != "" || dirName == "") {
+		return retry.RetryWithData(uri, headerParams, []string{files[0].Data}, httpCall)
+	}
+
+	concurrency, _ := flags.GetInt("concurrency")
+	failFast, _ := flags.GetBool("fail-fast")
+	return c.bulkUpsert(method, uri, headerParams, files, concurrency, failFast)
+}
+
+/* Checks each of the bodies against the command's body schema before any are sent, exiting with the problems found unless --skip-validation is set */
+func validateBodies(cmd *cobra.Command, files []utils.InputFile) {
+	if skip, _ := cmd.Flags().GetBool("skip-validation"); skip {
+		return
+	}
+	schema := utils.BodySchema(cmd)
+	var problems strings.Builder
+	for _, file := range files {
+		errors := validation.Validate(file.Data, schema)
+		if len(errors) == 0 {
+			continue
+		}
+		fmt.Fprintf(&problems, "%s is not valid:\n", file.Name)
+		for _, err := range errors {
+			fmt.Fprintf(&problems, "  %v\n", err)
+		}
+	}
+	if problems.Len() > 0 {
+		logger.Fatalf("%sUse --skip-validation to send the request anyway\n", problems.String())
+	}
+}
+
+// bulkUpsert sends each file's body through a pool of workers and returns the outcome of each, in the order of the files.
+// A models.BulkError holding the results is returned if any of the requests failed
+func (c *commandService) bulkUpsert(method string, uri string, headerParams map[string]string, files []utils.InputFile, concurrency int, failFast bool) func(retryConfig *retry.RetryConfiguration) (string, error) {
+	return func(retryConfig *retry.RetryConfiguration) (string, error) {
+		if retryConfig == nil {
+			retryConfig = retry.DefaultRetryConfiguration()
+		}
+		if concurrency < 1 {
+			concurrency = 1
+		}
+		// The workers share a copy of the configuration so a 429 seen by any of them holds all of them back
+		throttledConfig := *retryConfig
+		throttledConfig.Throttle = &retry.Throttle{}
+
+		// The client is shared by the workers, which pass the retry policy with each request and re-authenticate through the client
+		profileName, _ := c.cmd.Root().Flags().GetString("profile")
+		config, err := configGetConfig(profileName)
+		if err != nil {
+			return "", err
+		}
+		restClient := restclientNewRESTClient(config)
+		c.traceStart(method, uri, "")
+
+		results := make([]BulkResult, len(files))
+		var lock sync.Mutex
+		succeeded, failed := 0, 0
+
+		indexes := make(chan int)
+		var wg sync.WaitGroup
+		for w := 0; w < concurrency; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := range indexes {
+					results[i] = BulkResult{File: files[i].Name}
+					lock.Lock()
+					skip := failFast && failed > 0
+					lock.Unlock()
+					if skip {
+						results[i].Skipped = true
+						continue
+					}
+
+					response, err := callWithReAuthentication(config, restClient, method, uri, headerParams, files[i].Data, &throttledConfig)
+
+					lock.Lock()
+					if err != nil {
+						failed++
+					} else {
+						succeeded++
+					}
+					lock.Unlock()
+
+					if err != nil {
+						if httpErr, ok := err.(models.HttpStatusError); ok {
+							results[i].StatusCode = httpErr.StatusCode
+							results[i].CorrelationID = http.Header(httpErr.Headers).Get("Inin-Correlation-Id")
+						}
+						results[i].Error = rawJSON(err.Error())
+						continue
+					}
+					results[i].StatusCode = response.StatusCode
+					results[i].CorrelationID = response.CorrelationID
+					results[i].Response = rawJSON(response.Body)
+				}
+			}()
+		}
+		for i := range files {
+			indexes <- i
+		}
+		close(indexes)
+		wg.Wait()
+		c.traceEnd()
+
+		resultsJSON, err := json.Marshal(results)
+		if err != nil {
+			return "", err
+		}
+		if failed > 0 {
+			return "", models.BulkError{Results: string(resultsJSON), Succeeded: succeeded, Failed: failed}
+		}
+		return string(resultsJSON), nil
+	}
+}
@@ -0,0 +1,198 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/mypurecloud/platform-client-sdk-cli/build/gc/mocks"
+	"github.com/mypurecloud/platform-client-sdk-cli/build/gc/models"
+	"github.com/mypurecloud/platform-client-sdk-cli/build/gc/restclient"
+	"github.com/mypurecloud/platform-client-sdk-cli/build/gc/utils"
+	"github.com/spf13/cobra"
+)
+
+func TestBulkUpsert(t *testing.T) {
+	configGetConfig = mockGetConfig
+	restclientNewRESTClient = mockNewRESTClient
+	setRestClientDoMockForBulk()
+
+	c := commandService{
+		cmd: &cobra.Command{},
+	}
+	files := []utils.InputFile{
+		{Name: "users/1.json", Data: `{"name":"one"}`},
+		{Name: "users/2.json", Data: `{"name":"fail"}`},
+		{Name: "users/3.json", Data: `{"name":"three"}`},
+	}
+
+	_, err := c.bulkUpsert(http.MethodPost, "/api/v2/users", map[string]string{}, files, 1, false)(nil)
+	bulkErr, ok := err.(models.BulkError)
+	if !ok {
+		t.Fatalf("Expected a BulkError, got: %v", err)
+	}
+	if bulkErr.Succeeded != 2 || bulkErr.Failed != 1 || bulkErr.ExitCode() != models.ExitPartialFailure {
+		t.Errorf("Did not get the expected counts, got: %+v", bulkErr)
+	}
+	results := make([]BulkResult, 0)
+	if err := json.Unmarshal([]byte(bulkErr.Results), &results); err != nil {
+		t.Fatalf("Results are not a JSON array: %s", bulkErr.Results)
+	}
+	if len(results) != 3 {
+		t.Fatalf("Expected a result per file, got: %s", bulkErr.Results)
+	}
+	if results[0].File != "users/1.json" || results[0].StatusCode != http.StatusOK || results[0].CorrelationID != "corr-one" || string(results[0].Response) != `{"name":"one"}` {
+		t.Errorf("Did not get the expected result for the first file, got: %+v", results[0])
+	}
+	if results[1].StatusCode != http.StatusBadRequest || results[1].CorrelationID != "corr-fail" || string(results[1].Error) != `{"message":"invalid"}` || results[1].Response != nil {
+		t.Errorf("Did not get the expected result for the failing file, got: %+v", results[1])
+	}
+	if results[2].StatusCode != http.StatusOK || results[2].Skipped {
+		t.Errorf("Expected the file after the failure to be sent, got: %+v", results[2])
+	}
+
+	_, err = c.bulkUpsert(http.MethodPost, "/api/v2/users", map[string]string{}, files, 1, true)(nil)
+	bulkErr, _ = err.(models.BulkError)
+	results = make([]BulkResult, 0)
+	json.Unmarshal([]byte(bulkErr.Results), &results)
+	if bulkErr.Failed != 1 || !results[2].Skipped || results[2].StatusCode != 0 {
+		t.Errorf("Expected --fail-fast to skip the files after the failure, got: %s", bulkErr.Results)
+	}
+
+	_, err = c.bulkUpsert(http.MethodPost, "/api/v2/users", map[string]string{}, files, 3, false)(nil)
+	bulkErr, _ = err.(models.BulkError)
+	results = make([]BulkResult, 0)
+	json.Unmarshal([]byte(bulkErr.Results), &results)
+	for i, result := range results {
+		if result.File != files[i].Name {
+			t.Errorf("Did not keep the results of concurrent requests in the order of the files, got: %s", bulkErr.Results)
+			break
+		}
+	}
+	if bulkErr.Succeeded != 2 || bulkErr.Failed != 1 {
+		t.Errorf("Did not get the expected counts with concurrent requests, got: %+v", bulkErr)
+	}
+
+	response, err := c.bulkUpsert(http.MethodPost, "/api/v2/users", map[string]string{}, files[:1], 1, false)(nil)
+	if err != nil {
+		t.Fatalf("err should be nil, got: %s", err)
+	}
+	if response != `[{"file":"users/1.json","statusCode":200,"correlationId":"corr-one","response":{"name":"one"}}]` {
+		t.Errorf("Did not get the expected results, got: %s", response)
+	}
+}
+
+func TestBulkUpsertReAuthenticates(t *testing.T) {
+	restclient.OverridesApplied = mocks.OverridesApplied
+	restclient.UpdateOAuthToken = mocks.UpdateOAuthToken
+	configGetConfig = mockGetConfig
+	restclientNewRESTClient = mockNewRESTClient
+
+	// Every request is rejected until the client logs in again
+	var lock sync.Mutex
+	logins := 0
+	restclient.ClientDo = func(client *retryablehttp.Client, request *retryablehttp.Request) (*http.Response, error) {
+		if request.URL.Path == "/oauth/token" {
+			lock.Lock()
+			logins++
+			lock.Unlock()
+			// Hold the login back so the other workers are rejected while it's in progress
+			time.Sleep(20 * time.Millisecond)
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(`{"access_token": "new-token", "token_type": "bearer", "expires_in": 3600}`))}, nil
+		}
+		if request.Header.Get("Authorization") != "Bearer new-token" {
+			return &http.Response{StatusCode: http.StatusUnauthorized, Body: io.NopCloser(strings.NewReader(""))}, nil
+		}
+		body, _ := io.ReadAll(request.Body)
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(string(body)))}, nil
+	}
+
+	c := commandService{
+		cmd: &cobra.Command{},
+	}
+	files := []utils.InputFile{
+		{Name: "users/1.json", Data: `{"name":"one"}`},
+		{Name: "users/2.json", Data: `{"name":"two"}`},
+		{Name: "users/3.json", Data: `{"name":"three"}`},
+	}
+	if _, err := c.bulkUpsert(http.MethodPost, "/api/v2/users", map[string]string{}, files, 3, false)(nil); err != nil {
+		t.Fatalf("Expected every request to be sent again after re-authenticating, got: %v", err)
+	}
+	if logins != 1 {
+		t.Errorf("Expected the workers to share a single re-authentication, got %v logins", logins)
+	}
+}
+
+func TestBulkUpsertReAuthenticatesEachExpiry(t *testing.T) {
+	restclient.OverridesApplied = mocks.OverridesApplied
+	restclient.UpdateOAuthToken = mocks.UpdateOAuthToken
+	configGetConfig = mockGetConfig
+	restclientNewRESTClient = mockNewRESTClient
+
+	// Each login gets a new token, and the token expires again when the second file is sent
+	logins, validToken, expired := 0, "token-0", false
+	restclient.ClientDo = func(client *retryablehttp.Client, request *retryablehttp.Request) (*http.Response, error) {
+		if request.URL.Path == "/oauth/token" {
+			logins++
+			validToken = fmt.Sprintf("token-%v", logins)
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(fmt.Sprintf(`{"access_token": "%v", "token_type": "bearer", "expires_in": 3600}`, validToken)))}, nil
+		}
+		body, _ := io.ReadAll(request.Body)
+		if string(body) == `{"name":"two"}` && !expired {
+			expired, validToken = true, "expired"
+		}
+		if request.Header.Get("Authorization") != "Bearer "+validToken {
+			return &http.Response{StatusCode: http.StatusUnauthorized, Body: io.NopCloser(strings.NewReader(""))}, nil
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(string(body)))}, nil
+	}
+
+	c := commandService{
+		cmd: &cobra.Command{},
+	}
+	files := []utils.InputFile{
+		{Name: "users/1.json", Data: `{"name":"one"}`},
+		{Name: "users/2.json", Data: `{"name":"two"}`},
+		{Name: "users/3.json", Data: `{"name":"three"}`},
+	}
+	if _, err := c.bulkUpsert(http.MethodPost, "/api/v2/users", map[string]string{}, files, 1, false)(nil); err != nil {
+		t.Fatalf("Expected the client to re-authenticate each time its token expired, got: %v", err)
+	}
+	if logins != 2 {
+		t.Errorf("Expected a login for each expired token, got %v logins", logins)
+	}
+}
+
+// setRestClientDoMockForBulk sets the restclient.ClientDo method to echo request bodies and reject bodies named "fail"
+func setRestClientDoMockForBulk() {
+	restclient.ClientDo = func(client *retryablehttp.Client, request *retryablehttp.Request) (*http.Response, error) {
+		body, _ := io.ReadAll(request.Body)
+		data := struct{ Name string }{}
+		json.Unmarshal(body, &data)
+		// Answer the first file last so concurrent requests complete out of order
+		if data.Name == "one" {
+			time.Sleep(20 * time.Millisecond)
+		}
+
+		header := http.Header{}
+		header.Set("Inin-Correlation-Id", "corr-"+data.Name)
+		if data.Name == "fail" {
+			return &http.Response{
+				StatusCode: http.StatusBadRequest,
+				Header:     header,
+				Body:       io.NopCloser(strings.NewReader(`{"message":"invalid"}`)),
+			}, nil
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     header,
+			Body:       io.NopCloser(strings.NewReader(string(body))),
+		}, nil
+	}
+}
@@ -0,0 +1,169 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/mypurecloud/platform-client-sdk-cli/build/gc/logger"
+)
+
+// paginationCheckpoint records the last page processed by a paginated command so an interrupted run can be resumed with --resume.
+// It's only saved when pagination is interrupted by an error or a signal, or after each page with --checkpoint
+type paginationCheckpoint struct {
+	URI        string `json:"uri"`
+	Profile    string `json:"profile"`
+	Paginator  string `json:"paginator"`
+	PageURI    string `json:"pageUri"`
+	PageNumber int    `json:"pageNumber,omitempty"`
+	Cursor     string `json:"cursor,omitempty"`
+	StartIndex int    `json:"startIndex,omitempty"`
+	UpdatedAt  string `json:"updatedAt"`
+
+	path   string
+	resume bool
+	// Save after each page so a run that's killed can still be resumed
+	saveEachPage bool
+	// Held while the checkpoint is updated or saved, as it's saved from the signal handler
+	lock sync.Mutex
+}
+
+// checkpointDirectory is a variable to allow reassignment in unit tests
+var checkpointDirectory = func() string {
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, ".gc", "checkpoints")
+}
+
+func newPaginationCheckpoint(profileName string, uri string) *paginationCheckpoint {
+	sum := sha256.Sum256([]byte(profileName + uri))
+	return &paginationCheckpoint{
+		URI:     uri,
+		Profile: profileName,
+		path:    filepath.Join(checkpointDirectory(), fmt.Sprintf("%x.json", sum[:8])),
+	}
+}
+
+func loadPaginationCheckpoint(path string, profileName string, uri string) (*paginationCheckpoint, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read checkpoint: %v", err)
+	}
+
+	checkpoint := &paginationCheckpoint{}
+	if err = json.Unmarshal(data, checkpoint); err != nil {
+		return nil, fmt.Errorf("unable to parse checkpoint %v: %v", path, err)
+	}
+	if checkpoint.Profile != profileName {
+		return nil, fmt.Errorf("checkpoint %v was created with profile %v, not %v", path, checkpoint.Profile, profileName)
+	}
+	if checkpoint.URI != uri {
+		return nil, fmt.Errorf("checkpoint %v was created for %v, not %v", path, checkpoint.URI, uri)
+	}
+	if checkpoint.PageURI == "" {
+		return nil, fmt.Errorf("checkpoint %v does not contain a page to resume from", path)
+	}
+
+	checkpoint.path = path
+	checkpoint.resume = true
+	return checkpoint, nil
+}
+
+func (p *paginationCheckpoint) resuming() bool {
+	return p != nil && p.resume
+}
+
+// startURI returns the URI of the first page to request
+func (p *paginationCheckpoint) startURI(uri string) string {
+	if p.resuming() {
+		return p.PageURI
+	}
+	return uri
+}
+
+// update records the page that has just been processed, saving it with --checkpoint
+func (p *paginationCheckpoint) update(paginatorName string, page *Page) {
+	if p == nil {
+		return
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.Paginator = paginatorName
+	p.PageURI = page.URI
+	p.PageNumber = page.Listing.PageNumber
+	p.StartIndex = page.Listing.StartIndex
+	p.Cursor, _ = getCursorAndQueryParamName(page.Listing)
+	p.UpdatedAt = time.Now().Format(time.RFC3339)
+
+	if p.saveEachPage {
+		p.save()
+	}
+}
+
+/* Writes the checkpoint to its file. The lock must be held */
+func (p *paginationCheckpoint) save() bool {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(p.path), 0700)
+	}
+	if err == nil {
+		err = os.WriteFile(p.path, data, 0600)
+	}
+	if err != nil {
+		logger.Warnf("Unable to save pagination checkpoint %v: %v", p.path, err)
+		return false
+	}
+	return true
+}
+
+// remove deletes the checkpoint once pagination has completed
+func (p *paginationCheckpoint) remove() {
+	if p == nil {
+		return
+	}
+	if err := os.Remove(p.path); err != nil && !os.IsNotExist(err) {
+		logger.Warnf("Unable to remove pagination checkpoint %v: %v", p.path, err)
+	}
+}
+
+// interrupted saves the checkpoint and adds the resume instructions to an error that stopped pagination
+func (p *paginationCheckpoint) interrupted(err error) error {
+	if p == nil {
+		return err
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.PageURI == "" || !p.save() {
+		return err
+	}
+	return fmt.Errorf("%w\nPagination can be resumed from the last completed page with: --resume %v", err, p.path)
+}
+
+// watchSignals saves the checkpoint if the command is interrupted or terminated while paginating. The returned function stops watching
+func (p *paginationCheckpoint) watchSignals() func() {
+	if p == nil {
+		return func() {}
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	done := make(chan struct{})
+	go func() {
+		select {
+		case sig := <-signals:
+			logger.Fatal(p.interrupted(fmt.Errorf("pagination stopped by %v", sig)), "\n")
+		case <-done:
+		}
+	}()
+	return func() {
+		signal.Stop(signals)
+		close(done)
+	}
+}
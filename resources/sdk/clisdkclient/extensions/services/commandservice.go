@@ -1,13 +1,12 @@
 package services
 
 import (
+	"crypto/sha256"
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"net/url"
-	"regexp"
-	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/mypurecloud/platform-client-sdk-cli/build/gc/config"
@@ -25,6 +24,7 @@ type CommandService interface {
 	Head(uri string, headerParams map[string]string) (string, error)
 	List(uri string, headerParams map[string]string) (string, error)
 	Stream(uri string, headerParams map[string]string) (string, error)
+	NDJSON(uri string, headerParams map[string]string) (string, error)
 	Post(uri string, headerParams map[string]string, payload string) (string, error)
 	Patch(uri string, headerParams map[string]string, payload string) (string, error)
 	Put(uri string, headerParams map[string]string, payload string) (string, error)
@@ -35,22 +35,26 @@ type CommandService interface {
 type commandService struct {
 	cmd       *cobra.Command
 	startTime time.Time
+	// Number of pages fetched concurrently when listing entity-paginated resources
+	parallel int
+	// Condition applied to each page when entities are rendered as they are streamed
+	filterCondition string
+	// Progress of the current pagination, used to resume an interrupted run
+	checkpoint *paginationCheckpoint
+	// Pagination strategy and items key selected for the operation. The strategy is detected from the first page if not set
+	paginatorName string
+	itemsKey      string
+	// Limits set by --max-items and --max-pages. Zero means no limit
+	maxItems int
+	maxPages int
 }
 
-type paginationStyle int
-
-const (
-	entityPagination paginationStyle = iota
-	cursorPagination
-	indexPagination
-)
-
 // The following functions are added as variables to allow reassignment to mock functions in unit tests
 var (
 	configGetConfig         = config.GetConfig
 	restclientNewRESTClient = restclient.NewRESTClient
-	// This will be set if the application silently re-authenticates for a 401 error
-	hasReAuthenticated bool
+	// Creates the clients used by gc batch, which aren't shared with the rest of the CLI
+	restclientNewProfileRESTClient = restclient.NewProfileRESTClient
 )
 
 // NewCommandService initializes a new command Service object
@@ -59,417 +63,371 @@ func NewCommandService(cmd *cobra.Command) *commandService {
 }
 
 func (c *commandService) Stream(uri string, headerParams map[string]string) (string, error) {
-	profileName, _ := c.cmd.Root().Flags().GetString("profile")
-	config, err := configGetConfig(profileName)
-	consecutiveEmptyPages := 0
-	if err != nil {
-		return "", err
-	}
-
-	restClient := restclientNewRESTClient(config)
-
-	//Looks up first page
-	c.traceStart(http.MethodGet, uri, "")
-	data, err := restClient.Get(uri, headerParams)
-	if err != nil {
-		err = reAuthenticateIfNecessary(config, err)
-		if err != nil {
-			return "", err
-		}
-		return c.Stream(uri, headerParams)
-	}
-
-	utils.Render(data)
-
-	firstPage := &models.Entities{}
-	err = json.Unmarshal([]byte(data), firstPage)
-	if err != nil {
-		return "", err
-	}
-
-	pageDataMap := make(map[string]bool, 0)
-	pageDataMap[data] = true
-
-	switch determinePaginationStyle(firstPage) {
-	case cursorPagination:
-		cursor, cursorQueryParamName := getCursorAndQueryParamName(firstPage)
-		pagedURI := updateCursorPagingURI(uri, cursorQueryParamName, cursor)
-		//This verification is necessary for some API endpoints with infinite nextUri like getting organizations limits changerequests endpoint
-		nbPageResults := getPageObjectsLength(firstPage)
-		if nbPageResults == 0 {
-			consecutiveEmptyPages++
-		}
-		for ok := true; ok; ok = cursor != "" && consecutiveEmptyPages < 3 {
-			logger.Info("Paginating with URI: ", pagedURI)
-			c.traceProgress(pagedURI)
-			retryFunc := retry.Retry(pagedURI, headerParams, restClient.Get)
-			data, err = retryFunc(getPaginationRetryConfiguration())
+	err := c.paginate(uri, headerParams, func(page *Page) error {
+		// The page information is kept, with only the items matching the filter condition. A copy is filtered so pagination still sees every item
+		if c.filterCondition != "" && len(page.Items) > 0 {
+			entities, err := c.filterEntities(page.Items)
 			if err != nil {
-				return "", err
+				return err
 			}
-
-			pageData := &models.Entities{}
-			err = json.Unmarshal([]byte(data), pageData)
-			if err != nil {
-				return "", err
-			}
-			nbPageResults = getPageObjectsLength(pageData)
-			if nbPageResults == 0 {
-				consecutiveEmptyPages++
-			} else {
-				consecutiveEmptyPages = 0
-			}
-
-			if pageDataMap[data] {
-				break
-			}
-			pageDataMap[data] = true
-
-			utils.Render(data)
-			cursor, _ = getCursorAndQueryParamName(pageData)
-			if pageData.NextUri != "" {
-				pagedURI = pageData.NextUri
-			} else {
-				pagedURI = updateCursorPagingURI(pagedURI, cursorQueryParamName, cursor)
+			filteredPage := *page
+			if err = filteredPage.setItems(entities); err != nil {
+				return err
 			}
+			page = &filteredPage
 		}
-		break
-	case entityPagination:
-		pagedURI := uri
-		for x := 2; ; x++ {
-			pagedURI = updatePagingIndex(pagedURI, "pageNumber", x)
-			logger.Info("Paginating with URI: ", pagedURI)
-			c.traceProgress(pagedURI)
-			retryFunc := retry.Retry(pagedURI, headerParams, restClient.Get)
-			data, err = retryFunc(getPaginationRetryConfiguration())
-			if err != nil {
-				return "", err
-			}
-
-			pageData := &models.Entities{}
-			err = json.Unmarshal([]byte(data), pageData)
-			if err != nil {
-				return "", err
-			}
-
-			if len(pageData.Entities) == 0 {
-				break
-			}
+		utils.Render(page.Data)
+		return nil
+	})
+	return "", err
+}
 
-			if pageDataMap[data] {
-				break
-			}
-			pageDataMap[data] = true
+func (c *commandService) NDJSON(uri string, headerParams map[string]string) (string, error) {
+	err := c.paginate(uri, headerParams, c.renderPageEntities)
+	return "", err
+}
 
-			utils.Render(data)
+/* Renders each entity of a page on its own line, applying the filter condition to the page first if one was set */
+func (c *commandService) renderPageEntities(page *Page) error {
+	entities := page.Items
+	if c.filterCondition != "" {
+		var err error
+		if entities, err = c.filterEntities(entities); err != nil {
+			return err
 		}
-		break
-	case indexPagination:
-		pagedURI := uri
-		pageData := firstPage
-		for ok := true; ok; ok = len(getPageObjects(pageData)) > 0 {
-			pagedURI = updatePagingIndex(pagedURI, "startIndex", pageData.StartIndex)
-			logger.Info("Paginating with URI: ", pagedURI)
-			c.traceProgress(pagedURI)
-			retryFunc := retry.Retry(pagedURI, headerParams, restClient.Get)
-			data, err = retryFunc(getPaginationRetryConfiguration())
-			if err != nil {
-				return "", err
-			}
+	}
 
-			pageData = &models.Entities{}
-			err = json.Unmarshal([]byte(data), pageData)
-			if err != nil {
-				return "", err
-			}
+	for _, entity := range entities {
+		utils.RenderLine(string(entity))
+	}
+	return nil
+}
 
-			if pageDataMap[data] {
-				break
-			}
-			pageDataMap[data] = true
+/* Returns the entities of a page matching the filter condition */
+func (c *commandService) filterEntities(entities []json.RawMessage) ([]json.RawMessage, error) {
+	entitiesJSON, err := json.Marshal(entities)
+	if err != nil {
+		return nil, err
+	}
+	filtered, err := utils.FilterByCondition(string(entitiesJSON), c.filterCondition)
+	if err != nil {
+		return nil, err
+	}
+	matched := make([]json.RawMessage, 0)
+	if err = json.Unmarshal([]byte(filtered), &matched); err != nil {
+		return nil, err
+	}
+	// No matches are returned as null
+	if matched == nil {
+		matched = make([]json.RawMessage, 0)
+	}
+	return matched, nil
+}
 
-			if len(getPageObjects(pageData)) > 0 {
-				utils.Render(data)
-			}
+func (c *commandService) List(uri string, headerParams map[string]string) (string, error) {
+	totalResults := make([]string, 0)
+	err := c.paginate(uri, headerParams, func(page *Page) error {
+		for _, val := range page.Items {
+			totalResults = append(totalResults, string(val))
 		}
-		break
+		return nil
+	})
+	if err != nil {
+		return "", err
 	}
 
-	c.traceEnd()
+	//Convert the data into one big string
+	var finalJSONString string
+	switch len(totalResults) {
+	case 0:
+		finalJSONString = "[]"
+	case 1:
+		finalJSONString = fmt.Sprintf("[%s]", totalResults[0])
+	default:
+		finalJSONString = fmt.Sprintf("[%s]", strings.Join(totalResults, ","))
+	}
 
-	return "", nil
+	return finalJSONString, nil
 }
 
-func (c *commandService) List(uri string, headerParams map[string]string) (string, error) {
+/* Paginates through the pages of the list, saving a checkpoint to resume from if pagination doesn't complete */
+func (c *commandService) paginate(uri string, headerParams map[string]string, handle func(page *Page) error) error {
+	defer c.checkpoint.watchSignals()()
+	if err := c.paginatePages(uri, headerParams, handle); err != nil {
+		return c.checkpoint.interrupted(err)
+	}
+	c.checkpoint.remove()
+	return nil
+}
+
+/* Paginates through the results, passing the first page and each later page that contains items to handle as soon as it is retrieved */
+func (c *commandService) paginatePages(uri string, headerParams map[string]string, handle func(page *Page) error) error {
 	profileName, _ := c.cmd.Root().Flags().GetString("profile")
 	config, err := configGetConfig(profileName)
-	consecutiveEmptyPages := 0
 	if err != nil {
-		return "", err
+		return err
 	}
 
 	restClient := restclientNewRESTClient(config)
+	retryConfig := newRetryConfiguration(config)
 
 	//Looks up first page
 	c.traceStart(http.MethodGet, uri, "")
-	data, err := restClient.Get(uri, headerParams)
+	firstPageURI := c.checkpoint.startURI(uri)
+	response, err := callWithReAuthentication(config, restClient, http.MethodGet, firstPageURI, headerParams, "", retryConfig)
 	if err != nil {
-		err = reAuthenticateIfNecessary(config, err)
-		if err != nil {
-			return "", err
-		}
-		return c.List(uri, headerParams)
+		return err
 	}
 
-	firstPage := &models.Entities{}
-	err = json.Unmarshal([]byte(data), firstPage)
+	data := response.Body
+
+	firstPage, err := newPage(firstPageURI, data, c.itemsKey)
 	if err != nil {
-		return "", err
+		return err
 	}
 
-	//Allocate the total results based on the page count
-	totalResults := make([]string, 0)
-	for _, val := range getPageObjects(firstPage) {
-		totalResults = append(totalResults, string(val))
-	}
-
-	//This map is necessary to avoid some APIs where the query string isn't working
-	pageDataMap := make(map[string]bool, 0)
-	pageDataMap[data] = true
-
-	//Looks up the rest of the pages
-	switch determinePaginationStyle(firstPage) {
-	case cursorPagination:
-		cursor, cursorQueryParamName := getCursorAndQueryParamName(firstPage)
-		pagedURI := updateCursorPagingURI(uri, cursorQueryParamName, cursor)
-		//This verification is necessary for some API endpoints with infinite nextUri like getting organizations limits changerequests endpoint
-		nbPageResults := getPageObjectsLength(firstPage)
-		if nbPageResults == 0 {
-			consecutiveEmptyPages++
-		}
-		for ok := true; ok; ok = cursor != "" && consecutiveEmptyPages < 3 {
-			logger.Info("Paginating with URI: ", pagedURI)
-			c.traceProgress(pagedURI)
-			retryFunc := retry.Retry(pagedURI, headerParams, restClient.Get)
-			data, err = retryFunc(getPaginationRetryConfiguration())
-			if err != nil {
-				return "", err
-			}
+	paginatorName := c.paginatorName
+	if c.checkpoint.resuming() {
+		paginatorName = c.checkpoint.Paginator
+	} else if paginatorName == "" {
+		paginatorName = detectPaginator(firstPage)
+	}
+	paginator, err := getPaginator(paginatorName)
+	if err != nil {
+		return err
+	}
 
-			pageData := &models.Entities{}
-			err = json.Unmarshal([]byte(data), pageData)
-			if err != nil {
-				return "", err
+	// Output the page, returning false once the --max-items or --max-pages limit has been reached
+	pagesProcessed, itemsProcessed := 0, 0
+	emit := func(page *Page) (bool, error) {
+		if c.maxItems > 0 && itemsProcessed+len(page.Items) > c.maxItems {
+			if err := page.truncate(c.maxItems - itemsProcessed); err != nil {
+				return false, err
 			}
-			nbPageResults = getPageObjectsLength(pageData)
-			if nbPageResults == 0 {
-				consecutiveEmptyPages++
-			} else {
-				consecutiveEmptyPages = 0
+		}
+		// The first page is output even if it's empty, so streamed output still shows the page information
+		if len(page.Items) > 0 || pagesProcessed == 0 {
+			if err := handle(page); err != nil {
+				return false, err
 			}
+		}
+		pagesProcessed++
+		itemsProcessed += len(page.Items)
+		return !c.limitReached(pagesProcessed, itemsProcessed), nil
+	}
 
-			if pageDataMap[data] {
-				break
-			}
-			pageDataMap[data] = true
-			for _, val := range getPageObjects(pageData) {
-				totalResults = append(totalResults, string(val))
-			}
-			cursor, _ = getCursorAndQueryParamName(pageData)
-			if pageData.NextUri != "" {
-				pagedURI = pageData.NextUri
-			} else {
-				pagedURI = updateCursorPagingURI(pagedURI, cursorQueryParamName, cursor)
-			}
+	// The checkpointed page was already processed before the run was interrupted
+	more := true
+	if !c.checkpoint.resuming() {
+		if more, err = emit(firstPage); err != nil {
+			return err
 		}
-		break
-	case entityPagination:
-		pagedURI := uri
-		for x := 2; ; x++ {
-			pagedURI = updatePagingIndex(pagedURI, "pageNumber", x)
-			logger.Info("Paginating with URI: ", pagedURI)
-			c.traceProgress(pagedURI)
-			retryFunc := retry.Retry(pagedURI, headerParams, restClient.Get)
-			data, err = retryFunc(getPaginationRetryConfiguration())
-			if err != nil {
-				return "", err
-			}
+	}
+	c.checkpoint.update(paginatorName, firstPage)
+	if !more {
+		c.traceEnd()
+		return nil
+	}
 
-			pageData := &models.Entities{}
-			err = json.Unmarshal([]byte(data), pageData)
-			if err != nil {
-				return "", err
-			}
+	//This map is necessary to avoid some APIs where the query string isn't working. Only a digest of each page is kept so memory use doesn't grow with the number of pages
+	pageDataMap := make(map[[sha256.Size]byte]bool, 0)
+	pageDataMap[sha256.Sum256([]byte(data))] = true
 
-			if len(pageData.Entities) == 0 {
-				break
-			}
+	// Add the page to the results, returning false if pagination should stop
+	processPage := func(page *Page) (bool, error) {
+		pageKey := sha256.Sum256([]byte(page.Data))
+		if pageDataMap[pageKey] {
+			return false, nil
+		}
+		pageDataMap[pageKey] = true
 
-			if pageDataMap[data] {
-				break
-			}
-			pageDataMap[data] = true
-			for _, val := range getPageObjects(pageData) {
-				totalResults = append(totalResults, string(val))
-			}
+		more, err := emit(page)
+		if err != nil {
+			return false, err
 		}
-		break
-	case indexPagination:
-		pagedURI := uri
-		pageData := firstPage
-		for ok := true; ok; ok = len(getPageObjects(pageData)) > 0 {
-			pagedURI = updatePagingIndex(pagedURI, "startIndex", pageData.StartIndex)
-			logger.Info("Paginating with URI: ", pagedURI)
-			c.traceProgress(pagedURI)
-			retryFunc := retry.Retry(pagedURI, headerParams, restClient.Get)
-			data, err = retryFunc(getPaginationRetryConfiguration())
-			if err != nil {
-				return "", err
+		c.checkpoint.update(paginatorName, page)
+		return more, nil
+	}
+
+	if concurrentPaginator, ok := paginator.(ConcurrentPaginator); ok && c.parallel > 1 {
+		pageURIs := c.limitPageURIs(concurrentPaginator.PageURIs(firstPage), pagesProcessed, itemsProcessed, len(firstPage.Items))
+		if len(pageURIs) > 1 {
+			pages, fetchErr := c.fetchPagesConcurrently(pageURIs, headerParams, restClient, retryConfig)
+			for i, data := range pages {
+				// Pages after a failed request are not fetched
+				if data == "" {
+					break
+				}
+
+				page, err := newPage(pageURIs[i], data, c.itemsKey)
+				if err != nil {
+					return err
+				}
+				if len(page.Items) == 0 {
+					break
+				}
+				if ok, err := processPage(page); !ok {
+					if err != nil {
+						return err
+					}
+					break
+				}
 			}
-
-			pageData = &models.Entities{}
-			err = json.Unmarshal([]byte(data), pageData)
-			if err != nil {
-				return "", err
+			if fetchErr != nil {
+				return fetchErr
 			}
 
-			if pageDataMap[data] {
-				break
-			}
-			pageDataMap[data] = true
-			for _, val := range getPageObjects(pageData) {
-				totalResults = append(totalResults, string(val))
-			}
+			c.traceEnd()
+			return nil
 		}
-		break
 	}
 
-	//Convert the data into one big string
-	var finalJSONString string
-	switch len(totalResults) {
-	case 0:
-		finalJSONString = "[]"
-	case 1:
-		finalJSONString = fmt.Sprintf("[%s]", totalResults[0])
-	default:
-		finalJSONString = fmt.Sprintf("[%s]", strings.Join(totalResults, ","))
-	}
-
-	c.traceEnd()
-
-	return finalJSONString, nil
-}
-
-/* Get the cursor that points to the next item and the query param name (could be 'cursor' or 'after') */
-func getCursorAndQueryParamName(entities *models.Entities) (string, string) {
-	var (
-		cursor = "cursor"
-		after  = "after"
-	)
-	if entities.Cursor != "" {
-		return entities.Cursor, cursor
-	}
+	//Looks up the rest of the pages. This verification of empty pages is necessary for some API endpoints with infinite nextUri like getting organizations limits changerequests endpoint
+	consecutiveEmptyPages := 0
+	if len(firstPage.Items) == 0 {
+		consecutiveEmptyPages++
+	}
+	for pagedURI := paginator.NextURI(firstPage); pagedURI != "" && consecutiveEmptyPages < 3; {
+		logger.Info("Paginating with URI: ", pagedURI)
+		c.traceProgress(pagedURI)
+		retryFunc := retry.Retry(pagedURI, headerParams, restClient.Get)
+		data, err = retryFunc(retryConfig)
+		if err != nil {
+			return err
+		}
 
-	if entities.NextUri != "" {
-		u, _ := url.Parse(entities.NextUri)
-		m, _ := url.ParseQuery(u.RawQuery)
-		if cursorArray, ok := m[cursor]; ok && len(cursorArray) > 0 {
-			return cursorArray[0], cursor
+		page, err := newPage(pagedURI, data, c.itemsKey)
+		if err != nil {
+			return err
 		}
-		if afterArray, ok := m[after]; ok && len(afterArray) > 0 {
-			return afterArray[0], after
+		if len(page.Items) == 0 {
+			consecutiveEmptyPages++
+		} else {
+			consecutiveEmptyPages = 0
 		}
-	}
 
-	return entities.Cursors.After, after
-}
-
-func getPageObjects(entities *models.Entities) []json.RawMessage {
-	if len(entities.Resources) > 0 {
-		return entities.Resources
+		if ok, err := processPage(page); !ok {
+			if err != nil {
+				return err
+			}
+			break
+		}
+		pagedURI = paginator.NextURI(page)
 	}
 
-	if len(entities.Entities) > 0 {
-		return entities.Entities
-	}
+	c.traceEnd()
 
-	return entities.Conversations
+	return nil
 }
 
-func getPageObjectsLength(entities *models.Entities) int {
-	if len(entities.Resources) > 0 {
-		return len(entities.Resources)
-	}
-
-	if len(entities.Entities) > 0 {
-		return len(entities.Entities)
-	}
-
-	if len(entities.Conversations) > 0 {
-		return len(entities.Conversations)
-	}
-
-	return 0
+func (c *commandService) limitReached(pagesProcessed int, itemsProcessed int) bool {
+	return (c.maxPages > 0 && pagesProcessed >= c.maxPages) || (c.maxItems > 0 && itemsProcessed >= c.maxItems)
 }
 
-func updateCursorPagingURI(pagedURI, paramName, cursor string) string {
-	if strings.Contains(pagedURI, paramName+"=") {
-		re := regexp.MustCompile(paramName + "=([^&]*)")
-		result := re.FindStringSubmatch(pagedURI)
-		pagedURI = strings.Replace(pagedURI, result[0], fmt.Sprintf("%s=%v", paramName, url.QueryEscape(cursor)), 1)
-	} else {
-		if strings.Contains(pagedURI, "?") {
-			pagedURI = fmt.Sprintf("%s&%s=%s", pagedURI, paramName, url.QueryEscape(cursor))
-		} else {
-			pagedURI = fmt.Sprintf("%s?%s=%s", pagedURI, paramName, url.QueryEscape(cursor))
+/* Drops the page URIs that would go beyond the --max-pages or --max-items limits so they are never requested. The number of pages needed for the remaining items is estimated from the size of the first page */
+func (c *commandService) limitPageURIs(pageURIs []string, pagesProcessed int, itemsProcessed int, pageSize int) []string {
+	if c.maxPages > 0 && len(pageURIs) > c.maxPages-pagesProcessed {
+		pageURIs = pageURIs[:c.maxPages-pagesProcessed]
+	}
+	if c.maxItems > 0 && pageSize > 0 {
+		pagesNeeded := (c.maxItems - itemsProcessed + pageSize - 1) / pageSize
+		if len(pageURIs) > pagesNeeded {
+			pageURIs = pageURIs[:pagesNeeded]
 		}
 	}
-
-	return pagedURI
+	return pageURIs
 }
 
-func determinePaginationStyle(entities *models.Entities) paginationStyle {
-	if entities.Cursor != "" ||
-		entities.Cursors.After != "" ||
-		(entities.NextUri != "" && (strings.Contains(entities.NextUri, "cursor") || strings.Contains(entities.NextUri, "after"))) {
-		return cursorPagination
-	}
+/* Fetches the pages using a bounded pool of workers. Pages are returned in the same order as the URIs so the output matches a sequential listing. If a request fails, the pages fetched so far are returned along with the error */
+func (c *commandService) fetchPagesConcurrently(pageURIs []string, headerParams map[string]string, restClient *restclient.RESTClient, retryConfig *retry.RetryConfiguration) ([]string, error) {
+	pages := make([]string, len(pageURIs))
+	pageIndexes := make(chan int)
+	failed := make(chan struct{})
+
+	var (
+		wg        sync.WaitGroup
+		errorOnce sync.Once
+		firstErr  error
+	)
 
-	if entities.PageCount > 1 {
-		return entityPagination
+	workers := c.parallel
+	if workers > len(pages) {
+		workers = len(pages)
+	}
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for pageIndex := range pageIndexes {
+				pagedURI := pageURIs[pageIndex]
+				logger.Info("Paginating with URI: ", pagedURI)
+				c.traceProgress(pagedURI)
+				// The retry policy is passed with each request so the workers don't share it through retry.Do
+				response, err := restClient.CallWithRetry(http.MethodGet, pagedURI, headerParams, "", retryConfig)
+				if err != nil {
+					errorOnce.Do(func() {
+						firstErr = err
+						close(failed)
+					})
+					return
+				}
+				pages[pageIndex] = response.Body
+			}
+		}()
 	}
 
-	if entities.StartIndex != 0 {
-		return indexPagination
+dispatch:
+	for pageIndex := range pageURIs {
+		select {
+		case pageIndexes <- pageIndex:
+		case <-failed:
+			break dispatch
+		}
 	}
+	close(pageIndexes)
+	wg.Wait()
 
-	return entityPagination
+	return pages, firstErr
 }
 
-func getPaginationRetryConfiguration() *retry.RetryConfiguration {
-	return &retry.RetryConfiguration{
-		RetryWaitMax: 1000 * time.Second,
-		RetryWaitMin: 1000 * time.Second,
-		RetryMax:     100,
+// GetRetryConfiguration returns the retry policy of the profile the command is run with, falling back to the defaults if the profile can't be loaded
+func GetRetryConfiguration(cmd *cobra.Command) *retry.RetryConfiguration {
+	profileName, _ := cmd.Root().Flags().GetString("profile")
+	c, err := configGetConfig(profileName)
+	if err != nil {
+		return newRetryConfiguration(nil)
 	}
+	return newRetryConfiguration(c)
 }
 
-func updatePagingIndex(pagedURI, indexName string, index int) string {
-	if strings.Contains(pagedURI, fmt.Sprintf("%s=", indexName)) {
-		re := regexp.MustCompile(fmt.Sprintf("%s=([0-9]+)", indexName))
-		result := re.FindStringSubmatch(pagedURI)
-		index, _ := strconv.Atoi(result[1])
-		index++
-		pagedURI = strings.Replace(pagedURI, result[0], fmt.Sprintf("%s=%v", indexName, index), 1)
-	} else {
-		if strings.Contains(pagedURI, "?") {
-			pagedURI = fmt.Sprintf("%s&%s=%d", pagedURI, indexName, index)
-		} else {
-			pagedURI = fmt.Sprintf("%s?%s=%d", pagedURI, indexName, index)
+/* Builds the retry policy from the profile's retry settings and the --retry-max and --no-retry overrides */
+func newRetryConfiguration(c config.Configuration) *retry.RetryConfiguration {
+	retryConfig := retry.DefaultRetryConfiguration()
+
+	if c != nil && c.RetryConfiguration() != "" {
+		var profileRetryConfig config.RetryConfiguration
+		if err := json.Unmarshal([]byte(c.RetryConfiguration()), &profileRetryConfig); err != nil {
+			logger.Warnf("Unable to read the retry configuration of profile %v: %v", c.ProfileName(), err)
+		}
+		if profileRetryConfig.WaitMin != nil {
+			retryConfig.RetryWaitMin = time.Duration(*profileRetryConfig.WaitMin) * time.Second
+		}
+		if profileRetryConfig.WaitMax != nil {
+			retryConfig.RetryWaitMax = time.Duration(*profileRetryConfig.WaitMax) * time.Second
 		}
+		if profileRetryConfig.Max != nil {
+			retryConfig.RetryMax = *profileRetryConfig.Max
+		}
+		retryConfig.StatusRules = profileRetryConfig.StatusRules
 	}
 
-	return pagedURI
+	if config.RetryMax >= 0 {
+		retryConfig.RetryMax = config.RetryMax
+	}
+	if config.NoRetry {
+		retryConfig.RetryMax = 0
+	}
+	return retryConfig
 }
 
 func (c *commandService) Get(uri string, headerParams map[string]string) (string, error) {
@@ -497,72 +455,69 @@ func (c *commandService) Head(uri string, headerParams map[string]string) (strin
 }
 
 func (c *commandService) invoke(method string, uri string, headerParams map[string]string, payload string) (string, error) {
+	response, err := c.call(method, uri, headerParams, payload)
+	if err != nil {
+		return "", err
+	}
+	return response.Body, nil
+}
+
+/* Sends the request, re-authenticating once if the access token is no longer valid */
+func (c *commandService) call(method string, uri string, headerParams map[string]string, payload string) (*restclient.APIResponse, error) {
 	profileName, _ := c.cmd.Root().Flags().GetString("profile")
 	config, err := configGetConfig(profileName)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	restClient := restclientNewRESTClient(config)
 
-	var response string
-
-	c.traceStart(method, uri, payload)
 	switch method {
-	case http.MethodGet:
-		response, err = restClient.Get(uri, headerParams)
-	case http.MethodPost:
-		response, err = restClient.Post(uri, headerParams, payload)
-	case http.MethodPut:
-		response, err = restClient.Put(uri, headerParams, payload)
-	case http.MethodPatch:
-		response, err = restClient.Patch(uri, headerParams, payload)
-	case http.MethodDelete:
-		response, err = restClient.Delete(uri, headerParams)
-	case http.MethodHead:
-		response, err = restClient.Head(uri, headerParams)
+	case http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodHead:
 	default:
 		logger.Fatalf("Unable to resolve the http verb: %v", method)
 	}
 
+	c.traceStart(method, uri, payload)
+	response, err := callWithReAuthentication(config, restClient, method, uri, headerParams, payload, retry.GetRetryConfiguration())
+	if err != nil {
+		return nil, err
+	}
+	c.traceEnd()
+	return response, nil
+}
+
+/* Sends the request with the client, sending it again once the client has re-authenticated if its access token was rejected */
+func callWithReAuthentication(config config.Configuration, restClient *restclient.RESTClient, method string, uri string, headerParams map[string]string, payload string, retryConfig *retry.RetryConfiguration) (*restclient.APIResponse, error) {
+	token := restClient.AccessToken()
+	response, err := restClient.CallWithRetry(method, uri, headerParams, payload, retryConfig)
 	if err == nil {
-		c.traceEnd()
 		return response, nil
 	}
 
-	err = reAuthenticateIfNecessary(config, err)
+	err = reAuthenticateIfNecessary(config, restClient, token, err)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	return c.invoke(method, uri, headerParams, payload)
+	return restClient.CallWithRetry(method, uri, headerParams, payload, retryConfig)
 }
 
-func reAuthenticateIfNecessary(config config.Configuration, err error) error {
-	if hasReAuthenticated {
-		logger.Warn("Have already re-authenticated. Will not authenticate again")
+func reAuthenticateIfNecessary(config config.Configuration, restClient *restclient.RESTClient, rejectedToken string, err error) error {
+	if e, ok := err.(models.HttpStatusError); !ok || e.StatusCode != http.StatusUnauthorized {
 		return err
 	}
 
-	if e, ok := err.(models.HttpStatusError); ok && e.StatusCode == http.StatusUnauthorized {
-		// do not re-authenticate with client credentials if we have an access_token
-		if config.AccessToken() != "" {
-			logger.Warn("unauthorized. your access_token has either expired or is not valid. please authenticate")
-			err := fmt.Errorf("unauthorized. your access_token has either expired or is not valid. please authenticate")
-			return err
-		}
-		logger.Info("Received HTTP 401 error, re-authenticating")
-		_, err = restclient.ReAuthenticate(config)
-		if err != nil {
-			return err
-		}
-	} else {
+	// do not re-authenticate with client credentials if we have an access_token
+	if config.AccessToken() != "" {
+		logger.Warn("unauthorized. your access_token has either expired or is not valid. please authenticate")
+		err := fmt.Errorf("unauthorized. your access_token has either expired or is not valid. please authenticate")
 		return err
 	}
 
-	hasReAuthenticated = true
-
-	return nil
+	// The client only re-authenticates once for each token that was rejected, however many of its requests were rejected with it
+	logger.Info("Received HTTP 401 error, re-authenticating")
+	return restClient.ReAuthenticate(rejectedToken)
 }
 
 func (c *commandService) DetermineAction(httpMethod string, uri string, headerParams map[string]string, cmd *cobra.Command, opId string) func(retryConfiguration *retry.RetryConfiguration) (string, error) {
@@ -586,11 +541,42 @@ func (c *commandService) DetermineAction(httpMethod string, uri string, headerPa
 		// These flags will be false if they're not available on the command (simple GETs) or if they haven't been set on a paginatable command
 		autoPaginate, _ := flags.GetBool("autopaginate")
 		stream, _ := flags.GetBool("stream")
+		ndjson, _ := flags.GetBool("ndjson")
+		resume, _ := flags.GetString("resume")
+		c.parallel, _ = flags.GetInt("parallel")
+		c.maxItems, _ = flags.GetInt("max-items")
+		c.maxPages, _ = flags.GetInt("max-pages")
 
-		if !autoPaginate && !stream && !doAutoPagination {
+		if !autoPaginate && !stream && !ndjson && !doAutoPagination && resume == "" {
 			return retry.Retry(uri, headerParams, c.Get)
 		}
 
+		c.paginatorName = cmd.Annotations[PaginatorAnnotation]
+		c.itemsKey = cmd.Annotations[PaginatorItemsAnnotation]
+		if resume != "" {
+			checkpoint, err := loadPaginationCheckpoint(resume, profileName, uri)
+			if err != nil {
+				logger.Fatal(err)
+			}
+			c.checkpoint = checkpoint
+		} else {
+			c.checkpoint = newPaginationCheckpoint(profileName, uri)
+		}
+		c.checkpoint.saveEachPage, _ = flags.GetBool("checkpoint")
+
+		// Only the request for the first page is printed with --dry-run, as the rest depend on its response
+		if restclient.DryRun != "" {
+			return retry.Retry(c.checkpoint.startURI(uri), headerParams, c.Get)
+		}
+
+		// Streamed output is filtered page by page, as the pages are rendered
+		c.filterCondition, _ = flags.GetString("filtercondition")
+
+		// Output one entity per line as each page is retrieved
+		if ndjson {
+			return retry.Retry(uri, headerParams, c.NDJSON)
+		}
+
 		// Stream if the user just sets stream or stream and autopagination
 		if stream {
 			return retry.Retry(uri, headerParams, c.Stream)
@@ -600,20 +586,11 @@ func (c *commandService) DetermineAction(httpMethod string, uri string, headerPa
 	case http.MethodHead:
 		return retry.Retry(uri, headerParams, c.Head)
 	case http.MethodPatch:
-		if flags.Lookup("file") == nil || flags.Lookup("directory") == nil {
-			return retry.RetryWithData(uri, headerParams, []string{""}, c.Patch)
-		}
-		return retry.RetryWithData(uri, headerParams, utils.ResolveInputData(c.cmd), c.Patch)
+		return c.upsert(cmd, httpMethod, uri, headerParams, c.Patch)
 	case http.MethodPost:
-		if flags.Lookup("file") == nil || flags.Lookup("directory") == nil {
-			return retry.RetryWithData(uri, headerParams, []string{""}, c.Post)
-		}
-		return retry.RetryWithData(uri, headerParams, utils.ResolveInputData(c.cmd), c.Post)
+		return c.upsert(cmd, httpMethod, uri, headerParams, c.Post)
 	case http.MethodPut:
-		if flags.Lookup("file") == nil || flags.Lookup("directory") == nil {
-			return retry.RetryWithData(uri, headerParams, []string{""}, c.Put)
-		}
-		return retry.RetryWithData(uri, headerParams, utils.ResolveInputData(c.cmd), c.Put)
+		return c.upsert(cmd, httpMethod, uri, headerParams, c.Put)
 	case http.MethodDelete:
 		return retry.Retry(uri, headerParams, c.Delete)
 	}
@@ -4,8 +4,10 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"os"
 	"strconv"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -176,12 +178,313 @@ func TestReAuthentication(t *testing.T) {
 	}
 }
 
+func TestListParallel(t *testing.T) {
+	configGetConfig = mockGetConfig
+	restclientNewRESTClient = mockNewRESTClient
+	restclient.UpdateOAuthToken = mocks.UpdateOAuthToken
+
+	pageCount := 6
+	requestedPages := setRestClientDoMockForPagination(pageCount, 0)
+
+	c := commandService{
+		cmd:      &cobra.Command{},
+		parallel: 3,
+	}
+
+	results, err := c.List("/api/v2/users?pageSize=1", map[string]string{})
+	if err != nil {
+		t.Fatalf("err should be nil, got: %s", err)
+	}
+
+	expectedResults := make([]string, 0)
+	for x := 1; x <= pageCount; x++ {
+		expectedResults = append(expectedResults, fmt.Sprintf(`{"id":"%v"}`, x))
+	}
+	expected := fmt.Sprintf("[%s]", strings.Join(expectedResults, ","))
+	if results != expected {
+		t.Errorf("Did not get the results in page order, got: %s, want: %s.", results, expected)
+	}
+	if len(requestedPages) != pageCount {
+		t.Errorf("Did not request every page exactly once, got: %v, want: %v pages.", requestedPages, pageCount)
+	}
+}
+
+func TestNDJSON(t *testing.T) {
+	configGetConfig = mockGetConfig
+	restclientNewRESTClient = mockNewRESTClient
+	restclient.UpdateOAuthToken = mocks.UpdateOAuthToken
+
+	pageCount := 4
+	setRestClientDoMockForPagination(pageCount, 0)
+
+	c := commandService{
+		cmd:             &cobra.Command{},
+		filterCondition: "id!=3",
+	}
+
+	stdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+	_, err := c.NDJSON("/api/v2/users?pageSize=1", map[string]string{})
+	w.Close()
+	os.Stdout = stdout
+	if err != nil {
+		t.Fatalf("err should be nil, got: %s", err)
+	}
+
+	output, _ := io.ReadAll(r)
+	expected := "{\"id\":\"1\"}\n{\"id\":\"2\"}\n{\"id\":\"4\"}\n"
+	if string(output) != expected {
+		t.Errorf("Did not get one filtered entity per line, got: %q, want: %q.", output, expected)
+	}
+}
+
+func TestStreamFilterCondition(t *testing.T) {
+	configGetConfig = mockGetConfig
+	restclientNewRESTClient = mockNewRESTClient
+	restclient.UpdateOAuthToken = mocks.UpdateOAuthToken
+
+	stream := func(pageCount int) string {
+		setRestClientDoMockForPagination(pageCount, 0)
+		c := commandService{
+			cmd:             &cobra.Command{},
+			filterCondition: "id!=3",
+		}
+
+		stdout := os.Stdout
+		r, w, _ := os.Pipe()
+		os.Stdout = w
+		_, err := c.Stream("/api/v2/users?pageSize=1", map[string]string{})
+		w.Close()
+		os.Stdout = stdout
+		if err != nil {
+			t.Fatalf("err should be nil, got: %s", err)
+		}
+		output, _ := io.ReadAll(r)
+		return string(output)
+	}
+
+	output := stream(4)
+	if strings.Count(output, `"pageNumber"`) != 4 {
+		t.Errorf("Expected every page to be streamed, got: %s", output)
+	}
+	if strings.Contains(output, `"3"`) || !strings.Contains(output, `"4"`) {
+		t.Errorf("Expected the streamed pages to be filtered, got: %s", output)
+	}
+
+	// An empty first page is still output with its page information
+	output = stream(0)
+	if strings.Count(output, `"pageNumber"`) != 1 {
+		t.Errorf("Expected the empty first page to be streamed, got: %s", output)
+	}
+}
+
+func TestResumeFromCheckpoint(t *testing.T) {
+	configGetConfig = mockGetConfig
+	restclientNewRESTClient = mockNewRESTClient
+	restclient.UpdateOAuthToken = mocks.UpdateOAuthToken
+	checkpointDirectory = func() string {
+		return t.TempDir()
+	}
+
+	uri := "/api/v2/users?pageSize=1"
+	pageCount := 5
+	failingPage := 4
+	setRestClientDoMockForPagination(pageCount, failingPage)
+
+	c := commandService{
+		cmd:        &cobra.Command{},
+		checkpoint: newPaginationCheckpoint("", uri),
+	}
+	_, err := c.List(uri, map[string]string{})
+	if err == nil || !strings.Contains(err.Error(), "--resume "+c.checkpoint.path) {
+		t.Fatalf("Expected the error to explain how to resume, got: %v", err)
+	}
+
+	checkpoint, err := loadPaginationCheckpoint(c.checkpoint.path, "", uri)
+	if err != nil {
+		t.Fatalf("err should be nil, got: %s", err)
+	}
+	if checkpoint.PageNumber != failingPage-1 {
+		t.Errorf("Did not checkpoint the last completed page, got: %v, want: %v.", checkpoint.PageNumber, failingPage-1)
+	}
+	if _, err = loadPaginationCheckpoint(c.checkpoint.path, "other", uri); err == nil {
+		t.Errorf("Expected an error resuming a checkpoint with a different profile")
+	}
+
+	setRestClientDoMockForPagination(pageCount, 0)
+	c = commandService{
+		cmd:        &cobra.Command{},
+		checkpoint: checkpoint,
+	}
+	results, err := c.List(uri, map[string]string{})
+	if err != nil {
+		t.Fatalf("err should be nil, got: %s", err)
+	}
+	expected := `[{"id":"4"},{"id":"5"}]`
+	if results != expected {
+		t.Errorf("Did not resume after the checkpointed page, got: %s, want: %s.", results, expected)
+	}
+	if _, err = os.Stat(checkpoint.path); !os.IsNotExist(err) {
+		t.Errorf("Expected the checkpoint to be removed after pagination completed")
+	}
+}
+
+func TestCheckpointSavedWhenRequested(t *testing.T) {
+	configGetConfig = mockGetConfig
+	restclientNewRESTClient = mockNewRESTClient
+	restclient.UpdateOAuthToken = mocks.UpdateOAuthToken
+	checkpointDirectory = func() string {
+		return t.TempDir()
+	}
+
+	uri := "/api/v2/users?pageSize=1"
+	pageCount := 3
+	setRestClientDoMockForPagination(pageCount, 0)
+
+	for _, saveEachPage := range []bool{false, true} {
+		c := commandService{
+			cmd:        &cobra.Command{},
+			checkpoint: newPaginationCheckpoint("", uri),
+		}
+		c.checkpoint.saveEachPage = saveEachPage
+
+		pages := 0
+		err := c.paginate(uri, map[string]string{}, func(page *Page) error {
+			// The first page is only checkpointed once it has been processed
+			pages++
+			if pages == 1 {
+				return nil
+			}
+			if _, err := os.Stat(c.checkpoint.path); os.IsNotExist(err) == saveEachPage {
+				t.Errorf("Expected the checkpoint to be saved while paginating only with --checkpoint, saved: %v", !saveEachPage)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("err should be nil, got: %s", err)
+		}
+		if pages != pageCount {
+			t.Errorf("Did not process every page, got: %v, want: %v.", pages, pageCount)
+		}
+		if _, err = os.Stat(c.checkpoint.path); !os.IsNotExist(err) {
+			t.Errorf("Expected the checkpoint to be removed after pagination completed")
+		}
+	}
+}
+
+func TestRetryConfiguration(t *testing.T) {
+	defer func() {
+		config.RetryMax, config.NoRetry = -1, false
+	}()
+
+	tests := []struct {
+		profileRetryConfig string
+		retryMax           int
+		noRetry            bool
+		expected           retry.RetryConfiguration
+	}{
+		{"", -1, false, retry.RetryConfiguration{RetryWaitMin: 5 * time.Second, RetryWaitMax: 60 * time.Second, RetryMax: 20}},
+		{`{"waitMin": 1, "max": 3, "statusRules": {"409": true, "503": false}}`, -1, false, retry.RetryConfiguration{RetryWaitMin: 1 * time.Second, RetryWaitMax: 60 * time.Second, RetryMax: 3, StatusRules: map[int]bool{409: true, 503: false}}},
+		{`{"max": 3}`, 7, false, retry.RetryConfiguration{RetryWaitMin: 5 * time.Second, RetryWaitMax: 60 * time.Second, RetryMax: 7}},
+		{`{"max": 3}`, 7, true, retry.RetryConfiguration{RetryWaitMin: 5 * time.Second, RetryWaitMax: 60 * time.Second, RetryMax: 0}},
+	}
+
+	for _, tc := range tests {
+		c, _ := mockGetConfig("")
+		c.(*mocks.MockClientConfig).RetryConfigurationFunc = func() string {
+			return tc.profileRetryConfig
+		}
+		config.RetryMax, config.NoRetry = tc.retryMax, tc.noRetry
+
+		retryConfig := newRetryConfiguration(c)
+		if retryConfig.RetryWaitMin != tc.expected.RetryWaitMin || retryConfig.RetryWaitMax != tc.expected.RetryWaitMax || retryConfig.RetryMax != tc.expected.RetryMax {
+			t.Errorf("Did not build the right retry policy for %v, got: %+v, want: %+v.", tc.profileRetryConfig, *retryConfig, tc.expected)
+		}
+		if fmt.Sprint(retryConfig.StatusRules) != fmt.Sprint(tc.expected.StatusRules) {
+			t.Errorf("Did not read the status rules from %v, got: %v, want: %v.", tc.profileRetryConfig, retryConfig.StatusRules, tc.expected.StatusRules)
+		}
+	}
+}
+
+func TestPaginateRetryConfiguration(t *testing.T) {
+	configGetConfig = mockGetConfig
+	restclientNewRESTClient = mockNewRESTClient
+	restclient.UpdateOAuthToken = mocks.UpdateOAuthToken
+	config.RetryMax = 2
+	defer func() {
+		config.RetryMax = -1
+	}()
+
+	// Every page, including the first, is sent with the retry policy of the profile and flags
+	setRestClientDoMockForPagination(2, 0)
+	paginationDo := restclient.ClientDo
+	retryMax := make(map[string]int)
+	restclient.ClientDo = func(client *retryablehttp.Client, request *retryablehttp.Request) (*http.Response, error) {
+		retryMax[request.URL.RawQuery] = client.RetryMax
+		return paginationDo(client, request)
+	}
+
+	c := commandService{
+		cmd: &cobra.Command{},
+	}
+	if _, err := c.List("/api/v2/users?pageSize=1", map[string]string{}); err != nil {
+		t.Fatalf("err should be nil, got: %s", err)
+	}
+	if _, ok := retryMax["pageSize=1"]; !ok || len(retryMax) < 2 {
+		t.Fatalf("Expected every page to be requested, got: %v", retryMax)
+	}
+	for query, max := range retryMax {
+		if max != 2 {
+			t.Errorf("Expected %v to be retried up to --retry-max times, got: %v", query, max)
+		}
+	}
+}
+
+// setRestClientDoMockForPagination sets the restclient.ClientDo method to serve entity-paginated pages with one entity each.
+// Earlier pages are answered more slowly so that concurrent requests complete out of order. Requests for failingPage return a server error
+func setRestClientDoMockForPagination(pageCount int, failingPage int) map[string]int {
+	var lock sync.Mutex
+	requestedPages := make(map[string]int)
+
+	restclient.ClientDo = func(client *retryablehttp.Client, request *retryablehttp.Request) (*http.Response, error) {
+		pageNumber := request.URL.Query().Get("pageNumber")
+		if pageNumber == "" {
+			pageNumber = "1"
+		}
+		lock.Lock()
+		requestedPages[pageNumber]++
+		lock.Unlock()
+
+		page, _ := strconv.Atoi(pageNumber)
+		time.Sleep(time.Duration(pageCount-page) * 10 * time.Millisecond)
+		if page == failingPage {
+			return &http.Response{
+				StatusCode: http.StatusInternalServerError,
+				Body:       io.NopCloser(strings.NewReader("")),
+			}, nil
+		}
+
+		entities := fmt.Sprintf(`[{"id":"%v"}]`, page)
+		if page > pageCount {
+			entities = "[]"
+		}
+		responseString := fmt.Sprintf(`{"entities": %v, "pageSize": 1, "pageNumber": %v, "total": %v, "pageCount": %v}`, entities, page, pageCount, pageCount)
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader(responseString)),
+		}, nil
+	}
+
+	return requestedPages
+}
+
 // setRestClientDoMockForRetry sets the restclient.ClientDo method for the commandservice Retry test
 func setRestClientDoMockForRetry(tc apiClientTest, numberOfFailedCalls int) {
 	numCalls := 0
 
 	//Building a Mock HTTP Functions
-	restclient.ClientDo = func(request *retryablehttp.Request) (*http.Response, error) {
+	restclient.ClientDo = func(client *retryablehttp.Client, request *retryablehttp.Request) (*http.Response, error) {
 		//Setting up the response body
 		response := &http.Response{
 			Header:     tc.targetHeaders,
@@ -197,7 +500,7 @@ func setRestClientDoMockForRetry(tc apiClientTest, numberOfFailedCalls int) {
 			}
 		}
 
-		for i := 0; i < restclient.Client.RetryMax; i++ {
+		for i := 0; i < client.RetryMax; i++ {
 			numCalls++
 
 			stringReader := strings.NewReader(fmt.Sprintf(`{"numRetries": "%v"}`, numCalls))
@@ -212,7 +515,7 @@ func setRestClientDoMockForRetry(tc apiClientTest, numberOfFailedCalls int) {
 				fmt.Println("sleeping for", time.Duration(time.Duration(retryAfterValue)*time.Millisecond))
 				time.Sleep(time.Duration(time.Duration(retryAfterValue) * time.Millisecond))
 			}
-			if time.Now().Sub(startTime) > restclient.Client.RetryWaitMax {
+			if time.Now().Sub(startTime) > client.RetryWaitMax {
 				break
 			}
 		}
@@ -226,7 +529,7 @@ func setRestClientDoMockForReAuthenticate(tc apiClientTest) {
 	numCalls := 0
 
 	//Building a Mock HTTP Functions
-	restclient.ClientDo = func(request *retryablehttp.Request) (*http.Response, error) {
+	restclient.ClientDo = func(client *retryablehttp.Client, request *retryablehttp.Request) (*http.Response, error) {
 		//Setting up the response body
 		responseString := ""
 		statusCode := 0
@@ -316,6 +619,22 @@ func mockGetConfig(profileName string) (config.Configuration, error) {
 		return ""
 	}
 
+	mockConfig.CredentialProcessFunc = func() string {
+		return ""
+	}
+
+	mockConfig.OrgNameFunc = func() string {
+		return ""
+	}
+
+	mockConfig.AssertionFileFunc = func() string {
+		return ""
+	}
+
+	mockConfig.AssertionProcessFunc = func() string {
+		return ""
+	}
+
 	mockConfig.OAuthTokenDataFunc = func() string {
 		return ""
 	}
@@ -332,6 +651,10 @@ func mockGetConfig(profileName string) (config.Configuration, error) {
 		return ""
 	}
 
+	mockConfig.RetryConfigurationFunc = func() string {
+		return ""
+	}
+
 	return mockConfig, nil
 }
 
@@ -379,5 +702,9 @@ func mockGetConfigWithAccessToken(profileName string) (config.Configuration, err
 		return "XNiJQrSf2YQmJODySCxG6HaVIE2lfZfJ35Y4JDh5L9YEBJOTG3p6szRyUvWVM7pDmziPHHcq9NW7e0KxN_lb6w" // this is a "bad" token for testing purposes
 	}
 
+	mockConfig.RetryConfigurationFunc = func() string {
+		return ""
+	}
+
 	return mockConfig, nil
 }
@@ -0,0 +1,306 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/mypurecloud/platform-client-sdk-cli/build/gc/models"
+)
+
+// Annotations set on generated commands to select the pagination strategy and the key holding each page's items
+const (
+	PaginatorAnnotation      = "paginator"
+	PaginatorItemsAnnotation = "paginatorItems"
+)
+
+// Paginator is a strategy for walking through the pages of a listing response
+type Paginator interface {
+	// Matches reports whether the strategy applies to a response, based on its first page
+	Matches(page *Page) bool
+	// NextURI returns the URI of the page following the given page, or an empty string if there are no more pages
+	NextURI(page *Page) string
+}
+
+// ConcurrentPaginator is implemented by strategies that can determine the URIs of all the remaining pages from the first page, allowing them to be fetched concurrently
+type ConcurrentPaginator interface {
+	Paginator
+	PageURIs(first *Page) []string
+}
+
+// Page is a single page of a listing response
+type Page struct {
+	URI     string
+	Data    string
+	Listing *models.Entities
+	Items   []json.RawMessage
+
+	itemsKey string
+}
+
+var (
+	paginators = make(map[string]Paginator)
+	// The order strategies are tried in when an operation doesn't specify one
+	paginatorOrder []string
+)
+
+func init() {
+	RegisterPaginator("cursor", cursorPaginator{})
+	RegisterPaginator("entity", entityPaginator{})
+	RegisterPaginator("index", indexPaginator{})
+	RegisterPaginator("nexturi", nextURIPaginator{})
+}
+
+// RegisterPaginator adds a pagination strategy that can be selected by name or detected from a response
+func RegisterPaginator(name string, paginator Paginator) {
+	if _, exists := paginators[name]; !exists {
+		paginatorOrder = append(paginatorOrder, name)
+	}
+	paginators[name] = paginator
+}
+
+func getPaginator(name string) (Paginator, error) {
+	paginator, ok := paginators[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown paginator: %v", name)
+	}
+	return paginator, nil
+}
+
+// detectPaginator returns the first registered strategy matching the first page, falling back to entity pagination
+func detectPaginator(first *Page) string {
+	for _, name := range paginatorOrder {
+		if paginators[name].Matches(first) {
+			return name
+		}
+	}
+	return "entity"
+}
+
+func newPage(uri string, data string, itemsKey string) (*Page, error) {
+	page := &Page{
+		URI:     uri,
+		Data:    data,
+		Listing: &models.Entities{},
+	}
+	err := json.Unmarshal([]byte(data), page.Listing)
+	if err != nil {
+		return nil, err
+	}
+
+	if itemsKey == "" {
+		page.Items, page.itemsKey = getPageObjects(page.Listing), getPageObjectsKey(page.Listing)
+		return page, nil
+	}
+	page.itemsKey = itemsKey
+
+	fields := make(map[string]json.RawMessage)
+	err = json.Unmarshal([]byte(data), &fields)
+	if err != nil {
+		return nil, err
+	}
+	if items, ok := fields[itemsKey]; ok {
+		err = json.Unmarshal(items, &page.Items)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read the page items from %v: %v", itemsKey, err)
+		}
+	}
+	return page, nil
+}
+
+// truncate keeps the first n items of the page, removing the rest from the page data too
+func (p *Page) truncate(n int) error {
+	if n >= len(p.Items) {
+		return nil
+	}
+	return p.setItems(p.Items[:n])
+}
+
+// setItems replaces the items of the page in the page data too
+func (p *Page) setItems(items []json.RawMessage) error {
+	p.Items = items
+
+	fields := make(map[string]json.RawMessage)
+	err := json.Unmarshal([]byte(p.Data), &fields)
+	if err != nil {
+		return err
+	}
+	fields[p.itemsKey], err = json.Marshal(p.Items)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+	p.Data = string(data)
+	return nil
+}
+
+/* Pages through results using the 'cursor' or 'after' query params */
+type cursorPaginator struct{}
+
+func (cursorPaginator) Matches(page *Page) bool {
+	listing := page.Listing
+	return listing.Cursor != "" ||
+		listing.Cursors.After != "" ||
+		(listing.NextUri != "" && (strings.Contains(listing.NextUri, "cursor") || strings.Contains(listing.NextUri, "after")))
+}
+
+func (cursorPaginator) NextURI(page *Page) string {
+	cursor, cursorQueryParamName := getCursorAndQueryParamName(page.Listing)
+	if cursor == "" {
+		return ""
+	}
+	if page.Listing.NextUri != "" {
+		return page.Listing.NextUri
+	}
+	return updateCursorPagingURI(page.URI, cursorQueryParamName, cursor)
+}
+
+/* Pages through results using the 'pageNumber' query param until an empty page is returned */
+type entityPaginator struct{}
+
+func (entityPaginator) Matches(page *Page) bool {
+	return page.Listing.PageCount > 1
+}
+
+func (entityPaginator) NextURI(page *Page) string {
+	if len(page.Items) == 0 {
+		return ""
+	}
+	return updatePagingIndex(page.URI, "pageNumber", 2)
+}
+
+func (entityPaginator) PageURIs(first *Page) []string {
+	uris := make([]string, 0)
+	for pageNumber := first.Listing.PageNumber + 1; pageNumber <= first.Listing.PageCount; pageNumber++ {
+		if pageNumber < 2 {
+			continue
+		}
+		uris = append(uris, setPagingIndex(first.URI, "pageNumber", pageNumber))
+	}
+	return uris
+}
+
+/* Pages through results using the 'startIndex' query param */
+type indexPaginator struct{}
+
+func (indexPaginator) Matches(page *Page) bool {
+	return page.Listing.StartIndex != 0
+}
+
+func (indexPaginator) NextURI(page *Page) string {
+	if len(page.Items) == 0 {
+		return ""
+	}
+	return updatePagingIndex(page.URI, "startIndex", page.Listing.StartIndex)
+}
+
+/* Follows the 'nextUri' of each page, e.g. for endpoints that page with a 'pageToken' query param */
+type nextURIPaginator struct{}
+
+func (nextURIPaginator) Matches(page *Page) bool {
+	return page.Listing.NextUri != ""
+}
+
+func (nextURIPaginator) NextURI(page *Page) string {
+	if len(page.Items) == 0 {
+		return ""
+	}
+	return page.Listing.NextUri
+}
+
+/* Get the cursor that points to the next item and the query param name (could be 'cursor' or 'after') */
+func getCursorAndQueryParamName(entities *models.Entities) (string, string) {
+	var (
+		cursor = "cursor"
+		after  = "after"
+	)
+	if entities.Cursor != "" {
+		return entities.Cursor, cursor
+	}
+
+	if entities.NextUri != "" {
+		u, _ := url.Parse(entities.NextUri)
+		m, _ := url.ParseQuery(u.RawQuery)
+		if cursorArray, ok := m[cursor]; ok && len(cursorArray) > 0 {
+			return cursorArray[0], cursor
+		}
+		if afterArray, ok := m[after]; ok && len(afterArray) > 0 {
+			return afterArray[0], after
+		}
+	}
+
+	return entities.Cursors.After, after
+}
+
+func getPageObjects(entities *models.Entities) []json.RawMessage {
+	if len(entities.Resources) > 0 {
+		return entities.Resources
+	}
+
+	if len(entities.Entities) > 0 {
+		return entities.Entities
+	}
+
+	return entities.Conversations
+}
+
+func getPageObjectsKey(entities *models.Entities) string {
+	if len(entities.Resources) > 0 {
+		return "Resources"
+	}
+
+	if len(entities.Entities) > 0 {
+		return "entities"
+	}
+
+	return "conversations"
+}
+
+func updateCursorPagingURI(pagedURI, paramName, cursor string) string {
+	if strings.Contains(pagedURI, paramName+"=") {
+		re := regexp.MustCompile(paramName + "=([^&]*)")
+		result := re.FindStringSubmatch(pagedURI)
+		pagedURI = strings.Replace(pagedURI, result[0], fmt.Sprintf("%s=%v", paramName, url.QueryEscape(cursor)), 1)
+	} else {
+		if strings.Contains(pagedURI, "?") {
+			pagedURI = fmt.Sprintf("%s&%s=%s", pagedURI, paramName, url.QueryEscape(cursor))
+		} else {
+			pagedURI = fmt.Sprintf("%s?%s=%s", pagedURI, paramName, url.QueryEscape(cursor))
+		}
+	}
+
+	return pagedURI
+}
+
+func updatePagingIndex(pagedURI, indexName string, index int) string {
+	if strings.Contains(pagedURI, fmt.Sprintf("%s=", indexName)) {
+		re := regexp.MustCompile(fmt.Sprintf("%s=([0-9]+)", indexName))
+		result := re.FindStringSubmatch(pagedURI)
+		index, _ := strconv.Atoi(result[1])
+		index++
+		pagedURI = strings.Replace(pagedURI, result[0], fmt.Sprintf("%s=%v", indexName, index), 1)
+	} else {
+		if strings.Contains(pagedURI, "?") {
+			pagedURI = fmt.Sprintf("%s&%s=%d", pagedURI, indexName, index)
+		} else {
+			pagedURI = fmt.Sprintf("%s?%s=%d", pagedURI, indexName, index)
+		}
+	}
+
+	return pagedURI
+}
+
+func setPagingIndex(pagedURI, indexName string, index int) string {
+	if strings.Contains(pagedURI, fmt.Sprintf("%s=", indexName)) {
+		re := regexp.MustCompile(fmt.Sprintf("%s=([0-9]*)", indexName))
+		return re.ReplaceAllString(pagedURI, fmt.Sprintf("%s=%d", indexName, index))
+	}
+
+	return updatePagingIndex(pagedURI, indexName, index)
+}
@@ -0,0 +1,117 @@
+package services
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/mypurecloud/platform-client-sdk-cli/build/gc/mocks"
+	"github.com/mypurecloud/platform-client-sdk-cli/build/gc/restclient"
+	"github.com/spf13/cobra"
+)
+
+func TestDetectPaginator(t *testing.T) {
+	tests := []struct {
+		data     string
+		expected string
+	}{
+		{`{"entities": [{}], "pageNumber": 1, "pageCount": 3}`, "entity"},
+		{`{"entities": [{}], "cursor": "abc"}`, "cursor"},
+		{`{"entities": [{}], "nextUri": "/api/v2/things?after=abc"}`, "cursor"},
+		{`{"Resources": [{}], "startIndex": 1}`, "index"},
+		{`{"results": [{}], "nextUri": "/api/v2/things?pageToken=abc"}`, "nexturi"},
+		{`{"entities": [{}], "pageNumber": 1, "pageCount": 1}`, "entity"},
+	}
+
+	for _, tc := range tests {
+		page, err := newPage("/api/v2/things", tc.data, "")
+		if err != nil {
+			t.Fatalf("err should be nil, got: %s", err)
+		}
+		if name := detectPaginator(page); name != tc.expected {
+			t.Errorf("Did not detect the right paginator for %s, got: %s, want: %s.", tc.data, name, tc.expected)
+		}
+	}
+}
+
+func TestListWithNextURIPaginator(t *testing.T) {
+	configGetConfig = mockGetConfig
+	restclientNewRESTClient = mockNewRESTClient
+	restclient.UpdateOAuthToken = mocks.UpdateOAuthToken
+
+	setRestClientDoMockForPageTokens()
+
+	c := commandService{
+		cmd:           &cobra.Command{},
+		paginatorName: "nexturi",
+		itemsKey:      "results",
+	}
+	results, err := c.List("/api/v2/things", map[string]string{})
+	if err != nil {
+		t.Fatalf("err should be nil, got: %s", err)
+	}
+
+	expected := fmt.Sprintf("[%s]", strings.Join([]string{`{"id":"1"}`, `{"id":"2"}`, `{"id":"3"}`, `{"id":"4"}`}, ","))
+	if results != expected {
+		t.Errorf("Did not follow every nextUri, got: %s, want: %s.", results, expected)
+	}
+}
+
+func TestListWithLimits(t *testing.T) {
+	configGetConfig = mockGetConfig
+	restclientNewRESTClient = mockNewRESTClient
+	restclient.UpdateOAuthToken = mocks.UpdateOAuthToken
+
+	tests := []struct {
+		maxItems         int
+		maxPages         int
+		expectedResults  string
+		expectedRequests int
+	}{
+		{maxItems: 3, expectedResults: `[{"id":"1"},{"id":"2"},{"id":"3"}]`, expectedRequests: 2},
+		{maxItems: 1, expectedResults: `[{"id":"1"}]`, expectedRequests: 1},
+		{maxPages: 2, expectedResults: `[{"id":"1"},{"id":"2"},{"id":"3"}]`, expectedRequests: 2},
+		{maxItems: 10, maxPages: 10, expectedResults: `[{"id":"1"},{"id":"2"},{"id":"3"},{"id":"4"}]`, expectedRequests: 3},
+	}
+
+	for _, tc := range tests {
+		requests := setRestClientDoMockForPageTokens()
+		c := commandService{
+			cmd:      &cobra.Command{},
+			itemsKey: "results",
+			maxItems: tc.maxItems,
+			maxPages: tc.maxPages,
+		}
+		results, err := c.List("/api/v2/things", map[string]string{})
+		if err != nil {
+			t.Fatalf("err should be nil, got: %s", err)
+		}
+		if results != tc.expectedResults {
+			t.Errorf("Did not get the right results for max items %v and max pages %v, got: %s, want: %s.", tc.maxItems, tc.maxPages, results, tc.expectedResults)
+		}
+		if *requests != tc.expectedRequests {
+			t.Errorf("Did not stop requesting pages for max items %v and max pages %v, got: %v requests, want: %v.", tc.maxItems, tc.maxPages, *requests, tc.expectedRequests)
+		}
+	}
+}
+
+// setRestClientDoMockForPageTokens sets the restclient.ClientDo method to serve pages linked by a pageToken in the nextUri, with the items under the "results" key
+func setRestClientDoMockForPageTokens() *int {
+	pages := map[string]string{
+		"":  `{"results": [{"id":"1"},{"id":"2"}], "nextUri": "/api/v2/things?pageToken=a"}`,
+		"a": `{"results": [{"id":"3"}], "nextUri": "/api/v2/things?pageToken=b"}`,
+		"b": `{"results": [{"id":"4"}]}`,
+	}
+	requests := 0
+	restclient.ClientDo = func(client *retryablehttp.Client, request *retryablehttp.Request) (*http.Response, error) {
+		requests++
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader(pages[request.URL.Query().Get("pageToken")])),
+		}, nil
+	}
+	return &requests
+}
@@ -0,0 +1,223 @@
+package snippet
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/mypurecloud/platform-client-sdk-cli/build/gc/config"
+	"github.com/mypurecloud/platform-client-sdk-cli/build/gc/logger"
+	"github.com/mypurecloud/platform-client-sdk-cli/build/gc/restclient"
+	"github.com/mypurecloud/platform-client-sdk-cli/build/gc/utils"
+	"github.com/spf13/cobra"
+)
+
+// Format is the language the request is printed in instead of being sent. Supported formats: curl, httpie, go
+var Format string
+
+// The placeholder used for the access token in snippets
+const accessToken = "your_access_token"
+
+// Operation describes the API operation a generated command calls
+type Operation struct {
+	// The API category, which names the platformclientv2 API the operation belongs to
+	Category string
+	// The operationId in the API definition, which names the platformclientv2 method
+	OperationID string
+	// The parameters in the order the platformclientv2 method takes them
+	Params []Param
+	// Whether the platformclientv2 method returns response data
+	ReturnsData bool
+}
+
+// Param is a parameter of an API operation
+type Param struct {
+	Name string
+	// path, query, header or body
+	In       string
+	DataType string
+}
+
+// Request is a request made by a generated command
+type Request struct {
+	Method  string
+	URI     string
+	Headers map[string]string
+	Body    string
+	// The values of the path, query and header parameters by parameter name
+	Values map[string]string
+}
+
+var nonAlphanumericRe = regexp.MustCompile(`[^A-Za-z0-9]+`)
+
+// Emit prints the requests the command would make as snippets in the configured format
+func Emit(cmd *cobra.Command, operation Operation, request Request) {
+	profileName, _ := cmd.Root().Flags().GetString("profile")
+	c, err := config.GetConfig(profileName)
+	if err != nil {
+		logger.Fatal(err)
+	}
+
+	bodies := []string{""}
+	if request.Method == http.MethodPost || request.Method == http.MethodPut || request.Method == http.MethodPatch {
+		flags := cmd.Flags()
+		if flags.Lookup("file") != nil && flags.Lookup("directory") != nil {
+			bodies = utils.ResolveInputData(cmd)
+		}
+	}
+
+	snippets := make([]string, 0, len(bodies))
+	for _, body := range bodies {
+		request.Body = body
+		snippet, err := Generate(Format, restclient.APIURL(c, ""), operation, request)
+		if err != nil {
+			logger.Fatal(err)
+		}
+		snippets = append(snippets, snippet)
+	}
+	fmt.Println(strings.Join(snippets, "\n\n"))
+}
+
+// Generate builds a snippet sending the request to the API at baseURL
+func Generate(format string, baseURL *url.URL, operation Operation, request Request) (string, error) {
+	requestURL, err := url.Parse(strings.TrimSuffix(baseURL.String(), "/") + request.URI)
+	if err != nil {
+		return "", err
+	}
+	// Sort the query params so the snippet is stable
+	requestURL.RawQuery = requestURL.Query().Encode()
+
+	headers := map[string]string{"Authorization": fmt.Sprintf("Bearer %s", accessToken)}
+	for name, value := range request.Headers {
+		if value != "" {
+			headers[name] = value
+		}
+	}
+
+	switch strings.ToLower(format) {
+	case "curl":
+		return utils.FormatCurl(request.Method, requestURL.String(), "", headers, request.Body), nil
+	case "httpie":
+		return utils.FormatHTTPie(request.Method, requestURL.String(), "", headers, request.Body), nil
+	case "go":
+		return generateGo(baseURL, operation, request)
+	}
+	return "", fmt.Errorf("unsupported snippet format: %v. Supported formats: curl, httpie, go", format)
+}
+
+/* Builds a Go program fragment calling the matching platformclientv2 method, following the examples in the Go SDK documentation */
+func generateGo(baseURL *url.URL, operation Operation, request Request) (string, error) {
+	if operation.OperationID == "" || operation.Category == "" {
+		return "", fmt.Errorf("the operation does not have a matching Go SDK method")
+	}
+	methodName := exportedName(operation.OperationID)
+
+	var snippet strings.Builder
+	snippet.WriteString("config := platformclientv2.GetDefaultConfiguration()\n")
+	snippet.WriteString(fmt.Sprintf("config.BasePath = %s\n", strconv.Quote(strings.TrimSuffix(baseURL.String(), "/"))))
+	snippet.WriteString(fmt.Sprintf("config.AccessToken = %s\n\n", strconv.Quote(accessToken)))
+	snippet.WriteString(fmt.Sprintf("apiInstance := platformclientv2.New%sApiWithConfig(config)\n\n", exportedName(operation.Category)))
+
+	args := make([]string, 0, len(operation.Params))
+	for _, param := range operation.Params {
+		declaration, err := goDeclaration(param, request)
+		if err != nil {
+			return "", err
+		}
+		snippet.WriteString(declaration)
+		args = append(args, param.Name)
+	}
+	if len(args) > 0 {
+		snippet.WriteString("\n")
+	}
+
+	results := "response, err"
+	if operation.ReturnsData {
+		results = "data, response, err"
+	}
+	snippet.WriteString(fmt.Sprintf("%s := apiInstance.%s(%s)\n", results, methodName, strings.Join(args, ", ")))
+	snippet.WriteString("if err != nil {\n")
+	snippet.WriteString(fmt.Sprintf("\tfmt.Printf(\"Error calling %s: %%v\\n\", err)\n", methodName))
+	snippet.WriteString("} else {\n")
+	if operation.ReturnsData {
+		snippet.WriteString("\tfmt.Printf(\"Response:\\n  Success: %v\\n  Status code: %v\\n  Correlation ID: %v\\n  Body: %v\\n\", response.IsSuccess, response.StatusCode, response.CorrelationID, data)\n")
+	} else {
+		snippet.WriteString("\tfmt.Printf(\"Response:\\n  Success: %v\\n  Status code: %v\\n  Correlation ID: %v\\n\", response.IsSuccess, response.StatusCode, response.CorrelationID)\n")
+	}
+	snippet.WriteString("}")
+	return snippet.String(), nil
+}
+
+/* Declares a variable holding the value of the parameter, or its zero value if it hasn't been set */
+func goDeclaration(param Param, request Request) (string, error) {
+	if param.In == "body" {
+		dataType := goModelType(param.DataType)
+		if request.Body == "" {
+			return fmt.Sprintf("var %s %s\n", param.Name, dataType), nil
+		}
+		return fmt.Sprintf("var %s %s\nif err := json.Unmarshal([]byte(%s), &%s); err != nil {\n\tpanic(err)\n}\n", param.Name, dataType, goRawString(request.Body), param.Name), nil
+	}
+
+	value, ok := request.Values[param.Name]
+	if !ok || value == "" {
+		return fmt.Sprintf("var %s %s\n", param.Name, param.DataType), nil
+	}
+
+	switch param.DataType {
+	case "string":
+		return fmt.Sprintf("%s := %s\n", param.Name, strconv.Quote(value)), nil
+	case "int", "int32", "int64", "float32", "float64":
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return "", fmt.Errorf("invalid value for %v: %v", param.Name, value)
+		}
+		return fmt.Sprintf("var %s %s = %s\n", param.Name, param.DataType, value), nil
+	case "bool":
+		boolValue, err := strconv.ParseBool(value)
+		if err != nil {
+			return "", fmt.Errorf("invalid value for %v: %v", param.Name, value)
+		}
+		return fmt.Sprintf("%s := %v\n", param.Name, boolValue), nil
+	case "[]string":
+		items := make([]string, 0)
+		for _, item := range strings.Split(value, ",") {
+			items = append(items, strconv.Quote(item))
+		}
+		return fmt.Sprintf("%s := []string{%s}\n", param.Name, strings.Join(items, ", ")), nil
+	case "time.Time":
+		return fmt.Sprintf("%s, _ := time.Parse(time.RFC3339, %s)\n", param.Name, strconv.Quote(value)), nil
+	}
+	return fmt.Sprintf("var %s %s // %s\n", param.Name, param.DataType, value), nil
+}
+
+/* Models are in the platformclientv2 package, e.g. []Createuser is []platformclientv2.Createuser */
+func goModelType(dataType string) string {
+	name := strings.TrimLeft(dataType, "[]*")
+	if name == "" || strings.Contains(name, ".") || unicode.IsLower(rune(name[0])) {
+		return dataType
+	}
+	return dataType[:len(dataType)-len(name)] + "platformclientv2." + name
+}
+
+func goRawString(value string) string {
+	value = strings.TrimSpace(value)
+	if strings.Contains(value, "`") {
+		return strconv.Quote(value)
+	}
+	return "`" + value + "`"
+}
+
+/* Converts an API category or operationId to the Go SDK naming, e.g. "External Contacts" is ExternalContacts and getUser is GetUser */
+func exportedName(name string) string {
+	var exported strings.Builder
+	for _, word := range nonAlphanumericRe.Split(name, -1) {
+		if word == "" {
+			continue
+		}
+		exported.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+	return exported.String()
+}
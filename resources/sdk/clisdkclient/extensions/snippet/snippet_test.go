@@ -0,0 +1,90 @@
+package snippet
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	baseURL, _ := url.Parse("https://api.mypurecloud.com")
+	operation := Operation{
+		Category:    "Users",
+		OperationID: "patchUser",
+		Params: []Param{
+			{Name: "userId", In: "path", DataType: "string"},
+			{Name: "expand", In: "query", DataType: "[]string"},
+			{Name: "pageSize", In: "query", DataType: "int"},
+			{Name: "body", In: "body", DataType: "Updateuser"},
+		},
+		ReturnsData: true,
+	}
+	request := Request{
+		Method:  "PATCH",
+		URI:     "/api/v2/users/abc?expand=skills%2Cgroups",
+		Headers: map[string]string{"Content-Type": "application/json"},
+		Body:    `{"name": "O'Brien"}`,
+		Values:  map[string]string{"userId": "abc", "expand": "skills,groups"},
+	}
+
+	tests := []struct {
+		format   string
+		expected string
+	}{
+		{"curl", `curl -X PATCH 'https://api.mypurecloud.com/api/v2/users/abc?expand=skills%2Cgroups' \
+  -H 'Authorization: Bearer your_access_token' \
+  -H 'Content-Type: application/json' \
+  --data-raw '{"name": "O'\''Brien"}'`},
+		{"httpie", `http PATCH 'https://api.mypurecloud.com/api/v2/users/abc?expand=skills%2Cgroups' \
+  'Authorization:Bearer your_access_token' \
+  'Content-Type:application/json' \
+  --raw '{"name": "O'\''Brien"}'`},
+		{"go", "config := platformclientv2.GetDefaultConfiguration()\n" +
+			"config.BasePath = \"https://api.mypurecloud.com\"\n" +
+			"config.AccessToken = \"your_access_token\"\n" +
+			"\n" +
+			"apiInstance := platformclientv2.NewUsersApiWithConfig(config)\n" +
+			"\n" +
+			"userId := \"abc\"\n" +
+			"expand := []string{\"skills\", \"groups\"}\n" +
+			"var pageSize int\n" +
+			"var body platformclientv2.Updateuser\n" +
+			"if err := json.Unmarshal([]byte(`{\"name\": \"O'Brien\"}`), &body); err != nil {\n" +
+			"\tpanic(err)\n" +
+			"}\n" +
+			"\n" +
+			"data, response, err := apiInstance.PatchUser(userId, expand, pageSize, body)\n" +
+			"if err != nil {\n" +
+			"\tfmt.Printf(\"Error calling PatchUser: %v\\n\", err)\n" +
+			"} else {\n" +
+			"\tfmt.Printf(\"Response:\\n  Success: %v\\n  Status code: %v\\n  Correlation ID: %v\\n  Body: %v\\n\", response.IsSuccess, response.StatusCode, response.CorrelationID, data)\n" +
+			"}"},
+	}
+
+	for _, tc := range tests {
+		snippet, err := Generate(tc.format, baseURL, operation, request)
+		if err != nil {
+			t.Fatalf("err should be nil, got: %s", err)
+		}
+		if snippet != tc.expected {
+			t.Errorf("Did not generate the right %v snippet, got:\n%s\nwant:\n%s", tc.format, snippet, tc.expected)
+		}
+	}
+
+	if _, err := Generate("python", baseURL, operation, request); err == nil {
+		t.Errorf("Expected an error for an unsupported snippet format")
+	}
+}
+
+func TestExportedName(t *testing.T) {
+	tests := map[string]string{
+		"Users":             "Users",
+		"External Contacts": "ExternalContacts",
+		"getUsersMe":        "GetUsersMe",
+		"Text-Bots":         "TextBots",
+	}
+	for name, expected := range tests {
+		if exported := exportedName(name); exported != expected {
+			t.Errorf("Did not convert %v to the Go SDK name, got: %v, want: %v.", name, exported, expected)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package transform_data
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/Masterminds/sprig"
+	"sigs.k8s.io/yaml"
+)
+
+/*
+BodyVariables returns the variables request bodies are rendered with. Environment variables are overridden by the variables in
+the files, in the order they're given, which are overridden by the key=value pairs in vars
+*/
+func BodyVariables(vars []string, varFiles []string) (map[string]interface{}, error) {
+	variables := make(map[string]interface{})
+	for _, env := range os.Environ() {
+		if key, value, found := strings.Cut(env, "="); found {
+			variables[key] = value
+		}
+	}
+
+	for _, varFile := range varFiles {
+		data, err := os.ReadFile(varFile)
+		if err != nil {
+			return nil, fmt.Errorf("Error reading variables file %s: %v", varFile, err)
+		}
+		// YAML is a superset of JSON, so this reads either
+		jsonData, err := yaml.YAMLToJSON(data)
+		if err != nil {
+			return nil, fmt.Errorf("Error reading variables file %s: %v", varFile, err)
+		}
+		fileVariables := make(map[string]interface{})
+		if err := json.Unmarshal(jsonData, &fileVariables); err != nil {
+			return nil, fmt.Errorf("Error reading variables file %s: expected a map of variable names to values", varFile)
+		}
+		for key, value := range fileVariables {
+			variables[key] = value
+		}
+	}
+
+	for _, v := range vars {
+		key, value, found := strings.Cut(v, "=")
+		if !found || key == "" {
+			return nil, fmt.Errorf("Invalid variable %q, expected key=value", v)
+		}
+		variables[key] = value
+	}
+	return variables, nil
+}
+
+// RenderBody renders the request body read from name as a Go template with the variables. Using a variable that isn't set is an error
+func RenderBody(name string, body string, variables map[string]interface{}) (string, error) {
+	tmpl, err := template.New(name).Funcs(sprig.TxtFuncMap()).Option("missingkey=error").Parse(body)
+	if err != nil {
+		return "", fmt.Errorf("Error parsing request body template: %v", err)
+	}
+	var rendered bytes.Buffer
+	if err := tmpl.Execute(&rendered, variables); err != nil {
+		return "", fmt.Errorf("Error rendering request body template: %v", err)
+	}
+	return rendered.String(), nil
+}
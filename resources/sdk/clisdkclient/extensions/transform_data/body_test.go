@@ -0,0 +1,46 @@
+package transform_data
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRenderBody(t *testing.T) {
+	t.Setenv("GC_TEST_DIVISION", "env-division")
+	t.Setenv("GC_TEST_ORG", "env-org")
+
+	varFile := filepath.Join(t.TempDir(), "vars.yaml")
+	if err := os.WriteFile(varFile, []byte("GC_TEST_ORG: file-org\nqueue:\n  name: Support\nskills: [a, b]\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	variables, err := BodyVariables([]string{"name=Ann \"A\"", "url=https://x?a=b"}, []string{varFile})
+	if err != nil {
+		t.Fatalf("err should be nil, got: %v", err)
+	}
+
+	body := `{"name": {{ .name | toJson }}, "division": "{{ .GC_TEST_DIVISION }}", "org": "{{ .GC_TEST_ORG }}", "queue": "{{ .queue.name }}", "skills": {{ .skills | toJson }}, "url": "{{ .url }}"}`
+	rendered, err := RenderBody("user.json", body, variables)
+	if err != nil {
+		t.Fatalf("err should be nil, got: %v", err)
+	}
+	expected := `{"name": "Ann \"A\"", "division": "env-division", "org": "file-org", "queue": "Support", "skills": ["a","b"], "url": "https://x?a=b"}`
+	if rendered != expected {
+		t.Errorf("Did not get the expected body.\nExpected: %s\nGot:      %s", expected, rendered)
+	}
+
+	if _, err := RenderBody("user.json", `{"name": "{{ .missing }}"}`, variables); err == nil || !strings.Contains(err.Error(), "missing") {
+		t.Errorf("Expected an error for a variable that isn't set, got: %v", err)
+	}
+	if _, err := RenderBody("user.json", `{"name": "{{ .name }"}`, variables); err == nil {
+		t.Error("Expected an error for an invalid template")
+	}
+	if _, err := BodyVariables([]string{"name"}, nil); err == nil {
+		t.Error("Expected an error for a variable without a value")
+	}
+	if _, err := BodyVariables(nil, []string{filepath.Join(t.TempDir(), "missing.yaml")}); err == nil {
+		t.Error("Expected an error for a missing variables file")
+	}
+}
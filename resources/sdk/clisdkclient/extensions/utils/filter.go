@@ -14,6 +14,7 @@ const (
 	TypeString         = "string"
 	TypeBoolean        = "bool"
 	TypeArray          = "array"
+	TypeDate           = "date"
 )
 
 const (
@@ -27,35 +28,19 @@ const (
 	matchOperator                    = "match"
 )
 
-var (
-	supportedOperators = []string{
-		equalsOperator,
-		notEqualsOperator,
-		lessThanEqualsOperator,
-		greaterThanEqualsOperator,
-		lessThanOperator,
-		greaterThanOperator,
-		containsOperator,
-		matchOperator,
-	}
-)
-
+// FilterByCondition returns the objects in the JSON array, or in the entities array of a JSON object, that match the condition
 func FilterByCondition(data string, condition string) (string, error) {
 	objects, err := getJsonObjectsFromString(data)
 	if err != nil {
 		return "", err
 	}
 
-	// Find operator in condition
-	operator := findOperatorInString(condition)
-	if operator == "" {
-		return "", unrecognizedConditionError(condition)
+	expression, err := parseFilterCondition(condition)
+	if err != nil {
+		return "", err
 	}
 
-	path, value := getFieldKeyAndValueFromConditionString(condition, operator)
-	keys := getKeysFromJsonFieldPath(path)
-
-	allMatchedObjects, err := findObjectsMatchingCondition(objects, keys, value, operator)
+	allMatchedObjects, err := findObjectsMatchingCondition(objects, expression)
 	if err != nil {
 		return "", err
 	}
@@ -69,26 +54,11 @@ func FilterByCondition(data string, condition string) (string, error) {
 	return string(jsonBytes), nil
 }
 
-func findObjectsMatchingCondition(returnedObjects []interface{}, keys []string, value string, operator string) ([]interface{}, error) {
-	var (
-		allMatchedObjects []interface{}
-		match             bool
-	)
+func findObjectsMatchingCondition(returnedObjects []interface{}, expression filterExpression) ([]interface{}, error) {
+	var allMatchedObjects []interface{}
 
 	for _, object := range returnedObjects {
-		currentObjectsValue, err := getReferencedValueFromMap(keys, object.(map[string]interface{}), value)
-		if err != nil {
-			return nil, err
-		}
-		// Field not found in current object
-		if currentObjectsValue == nil {
-			continue
-		}
-		if currentObjectArray, ok := currentObjectsValue.([]interface{}); ok {
-			match, err = fieldMatchesValueInArray(currentObjectArray, value, operator)
-		} else {
-			match, err = fieldMatchesValue(currentObjectsValue, value, operator)
-		}
+		match, err := expression.matches(object.(map[string]interface{}))
 		if err != nil {
 			return nil, err
 		}
@@ -157,6 +127,11 @@ func fieldMatchesValue(jsonValue interface{}, cliInputValue string, operator str
 }
 
 func compareStrings(jsonValue string, cliInputValue string, operator string) (bool, error) {
+	if operator != containsOperator && operator != matchOperator {
+		if jsonTime, cliTime, ok := parseDates(jsonValue, cliInputValue); ok {
+			return compareDates(jsonTime, cliTime, operator)
+		}
+	}
 	switch operator {
 	case equalsOperator:
 		return jsonValue == cliInputValue, nil
@@ -216,15 +191,6 @@ func compareBooleans(jsonValue bool, cliInputValue string, operator string) (boo
 	}
 }
 
-func findOperatorInString(text string) string {
-	for _, operator := range supportedOperators {
-		if strings.Contains(text, operator) {
-			return operator
-		}
-	}
-	return ""
-}
-
 func getJsonObjectsFromString(data string) ([]interface{}, error) {
 	var (
 		jsonData interface{}
@@ -246,11 +212,6 @@ func getJsonObjectsFromString(data string) ([]interface{}, error) {
 	return objects, nil
 }
 
-func getFieldKeyAndValueFromConditionString(condition string, operator string) (string, string) {
-	expressionSplit := strings.Split(condition, operator)
-	return expressionSplit[0], strings.TrimSpace(expressionSplit[1])
-}
-
 func getKeysFromJsonFieldPath(path string) []string {
 	var trimmedKeys []string
 	keys := strings.Split(path, ".")
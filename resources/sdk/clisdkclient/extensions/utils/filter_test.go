@@ -389,6 +389,106 @@ func TestFilterByConditionNestedJson(t *testing.T) {
 	}
 }
 
+func TestFilterByConditionCompound(t *testing.T) {
+	var (
+		object1Id = uuid.NewString()
+		object2Id = uuid.NewString()
+		object3Id = uuid.NewString()
+		object4Id = uuid.NewString()
+		data      = fmt.Sprintf(`
+[
+	{
+		"id": "%s",
+		"state": "active",
+		"email": "alice@corp.com",
+		"division": {"name": "Sales East"},
+		"manager": null,
+		"version": 3,
+		"dateHired": "2021-06-01T09:00:00.000Z",
+		"skills": ["Spanish", "Billing"]
+	},
+	{
+		"id": "%s",
+		"state": "active",
+		"email": "bob@example.com",
+		"division": {"name": "Support"},
+		"manager": {"id": "1"},
+		"version": 5,
+		"dateHired": "2024-02-15T09:00:00.000Z",
+		"skills": ["French"]
+	},
+	{
+		"id": "%s",
+		"state": "inactive",
+		"email": "carol@corp.com",
+		"division": {"name": "Sales West"},
+		"version": 7,
+		"dateHired": "2019-11-30T09:00:00Z"
+	},
+	{
+		"id": "%s",
+		"state": "deleted",
+		"email": "dan (old) && co@example.com",
+		"division": {"name": "Support"},
+		"version": 1,
+		"dateHired": "2024-01-01T00:00:00.000Z"
+	}
+]
+`, object1Id, object2Id, object3Id, object4Id)
+
+		testCases = []OperatorTestCaseStruct{
+			{"state == active && division.name contains Sales || email match .*@corp.com", []string{object1Id, object3Id}, []string{object2Id, object4Id}},
+			{"state == active && (division.name contains Sales || email match .*@example.com)", []string{object1Id, object2Id}, []string{object3Id, object4Id}},
+			{"!(state == active) && version > 2", []string{object3Id}, []string{object1Id, object2Id, object4Id}},
+			{"state in [active, 'deleted']", []string{object1Id, object2Id, object4Id}, []string{object3Id}},
+			{"skills in [French, German]", []string{object2Id}, []string{object1Id, object3Id, object4Id}},
+			{"manager exists", []string{object1Id, object2Id}, []string{object3Id, object4Id}},
+			{"!manager exists", []string{object3Id, object4Id}, []string{object1Id, object2Id}},
+			{"manager == null", []string{object1Id, object3Id, object4Id}, []string{object2Id}},
+			{"manager != null", []string{object2Id}, []string{object1Id, object3Id, object4Id}},
+			{"dateHired >= 2024-01-01", []string{object2Id, object4Id}, []string{object1Id, object3Id}},
+			{"dateHired < 2021-06-01T10:00:00Z && dateHired > 2020-01-01", []string{object1Id}, []string{object2Id, object3Id, object4Id}},
+			{"dateHired == 2024-01-01T00:00:00Z", []string{object4Id}, []string{object1Id, object2Id, object3Id}},
+			{`email == "dan (old) && co@example.com"`, []string{object4Id}, []string{object1Id, object2Id, object3Id}},
+			{"(email match ^(alice|carol)@ || version == 5)", []string{object1Id, object2Id, object3Id}, []string{object4Id}},
+		}
+	)
+
+	for _, test := range testCases {
+		if err := verifyValueReturnedWithCondition(data, test.condition, test.expectedValues, test.notExpectedValues); err != nil {
+			t.Errorf("ERROR: %v", err)
+		}
+	}
+}
+
+func TestFilterByConditionSyntaxError(t *testing.T) {
+	type ErrorTestCaseStruct struct {
+		condition     string
+		expectedError error
+	}
+
+	var (
+		data      = `[{"name": "Steve", "age": 21}]`
+		testCases = []ErrorTestCaseStruct{
+			{"name == Steve &&", filterSyntaxError("name == Steve &&", 17, "expected a field")},
+			{"(name == Steve || age > 3", filterSyntaxError("(name == Steve || age > 3", 26, "expected )")},
+			{"name == Steve) && age > 3", filterSyntaxError("name == Steve) && age > 3", 14, `unexpected ")"`)},
+			{"age > 3 && name = Steve", filterSyntaxError("age > 3 && name = Steve", 17, "expected an operator")},
+			{"name in Steve", filterSyntaxError("name in Steve", 9, "expected [ after in")},
+			{"name in [Steve", filterSyntaxError("name in [Steve", 15, "expected , or ] in list")},
+			{"name == 'Steve", filterSyntaxError("name == 'Steve", 9, "unterminated quoted value")},
+			{"name == 'Steve' x", filterSyntaxError("name == 'Steve' x", 17, `unexpected "x"`)},
+			{"name > 2020-01-01", invalidOperatorError(TypeString, greaterThanOperator)},
+		}
+	)
+
+	for _, test := range testCases {
+		if err := verifyInvalidOperationError(data, test.condition, test.expectedError); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
 func verifyInvalidOperationError(jsonData string, condition string, expectedError error) error {
 	expectedErrorStr := fmt.Sprintf("%v", expectedError)
 	err := verifyValueReturnedWithCondition(jsonData, condition, []string{}, []string{})
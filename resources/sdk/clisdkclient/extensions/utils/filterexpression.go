@@ -0,0 +1,412 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	andOperator    = "&&"
+	orOperator     = "||"
+	notOperator    = "!"
+	inOperator     = "in"
+	existsOperator = "exists"
+	nullValue      = "null"
+)
+
+// Symbolic operators are matched longest first so <= isn't read as <
+var (
+	symbolicOperators = []string{equalsOperator, notEqualsOperator, lessThanEqualsOperator, greaterThanEqualsOperator, lessThanOperator, greaterThanOperator}
+	keywordOperators  = []string{containsOperator, matchOperator, inOperator, existsOperator}
+	// ISO-8601 layouts compared as dates by the ordering operators
+	dateLayouts = []string{time.RFC3339Nano, "2006-01-02T15:04:05.000Z0700", "2006-01-02T15:04:05", "2006-01-02"}
+)
+
+/*
+A filter condition is one or more predicates combined with && and ||, negated with ! and grouped with parentheses.
+&& binds more tightly than ||. A predicate compares a field, given as a path of keys separated by full stops, to a value:
+
+	state == active && (division.name contains Sales || email match .*@corp.com)
+	!(routingStatus.status in [OFF_QUEUE, IDLE]) && manager exists && dateHired >= 2024-01-01
+
+Values run until the next &&, || or unbalanced closing parenthesis, so they can contain spaces and regular expression groups.
+Values containing && or || can be quoted with single or double quotes
+*/
+type filterExpression interface {
+	matches(object map[string]interface{}) (bool, error)
+}
+
+type andExpression struct {
+	left, right filterExpression
+}
+
+type orExpression struct {
+	left, right filterExpression
+}
+
+type notExpression struct {
+	expression filterExpression
+}
+
+type filterPredicate struct {
+	keys     []string
+	operator string
+	value    string
+	// The values of an in predicate
+	values []string
+	// Set when the value is an unquoted null
+	isNull bool
+}
+
+func (e andExpression) matches(object map[string]interface{}) (bool, error) {
+	match, err := e.left.matches(object)
+	if err != nil || !match {
+		return false, err
+	}
+	return e.right.matches(object)
+}
+
+func (e orExpression) matches(object map[string]interface{}) (bool, error) {
+	match, err := e.left.matches(object)
+	if err != nil || match {
+		return match, err
+	}
+	return e.right.matches(object)
+}
+
+func (e notExpression) matches(object map[string]interface{}) (bool, error) {
+	match, err := e.expression.matches(object)
+	return !match, err
+}
+
+func (p filterPredicate) matches(object map[string]interface{}) (bool, error) {
+	if p.operator == existsOperator {
+		_, found := lookupField(p.keys, object)
+		return found, nil
+	}
+	if p.isNull {
+		value, _ := lookupField(p.keys, object)
+		return (value == nil) == (p.operator == equalsOperator), nil
+	}
+
+	currentObjectsValue, err := getReferencedValueFromMap(p.keys, object, p.value)
+	if err != nil {
+		return false, err
+	}
+	// Field not found in current object
+	if currentObjectsValue == nil {
+		return false, nil
+	}
+
+	if p.operator == inOperator {
+		candidates := []interface{}{currentObjectsValue}
+		if currentObjectArray, ok := currentObjectsValue.([]interface{}); ok {
+			candidates = currentObjectArray
+		}
+		for _, candidate := range candidates {
+			for _, value := range p.values {
+				if match, err := fieldMatchesValue(candidate, value, equalsOperator); err != nil || match {
+					return match, err
+				}
+			}
+		}
+		return false, nil
+	}
+
+	if currentObjectArray, ok := currentObjectsValue.([]interface{}); ok {
+		return fieldMatchesValueInArray(currentObjectArray, p.value, p.operator)
+	}
+	return fieldMatchesValue(currentObjectsValue, p.value, p.operator)
+}
+
+/* Finds the value at the path, reporting whether every key was present even if the value is null */
+func lookupField(keys []string, object map[string]interface{}) (interface{}, bool) {
+	var current interface{} = object
+	for _, k := range keys {
+		currentMap, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if current, ok = currentMap[k]; !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+type filterParser struct {
+	condition string
+	position  int
+}
+
+func parseFilterCondition(condition string) (filterExpression, error) {
+	parser := &filterParser{condition: condition}
+	expression, err := parser.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	parser.skipSpaces()
+	if !parser.atEnd() {
+		return nil, parser.syntaxError(fmt.Sprintf("unexpected %q", parser.condition[parser.position:parser.position+1]))
+	}
+	return expression, nil
+}
+
+func (p *filterParser) parseOr() (filterExpression, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.consume(orOperator) {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orExpression{left, right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseAnd() (filterExpression, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.consume(andOperator) {
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andExpression{left, right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseUnary() (filterExpression, error) {
+	p.skipSpaces()
+	if p.consume(notOperator) {
+		expression, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notExpression{expression}, nil
+	}
+	if p.consume("(") {
+		expression, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.consume(")") {
+			return nil, p.syntaxError("expected )")
+		}
+		return expression, nil
+	}
+	return p.parsePredicate()
+}
+
+func (p *filterParser) parsePredicate() (filterExpression, error) {
+	p.skipSpaces()
+	start := p.position
+	for !p.atEnd() && isFieldPathChar(p.condition[p.position]) {
+		p.position++
+	}
+	if p.position == start {
+		if p.atEnd() {
+			return nil, p.syntaxError("expected a field")
+		}
+		return nil, p.syntaxError(fmt.Sprintf("expected a field, got %q", p.condition[p.position:p.position+1]))
+	}
+	predicate := filterPredicate{keys: getKeysFromJsonFieldPath(p.condition[start:p.position])}
+
+	p.skipSpaces()
+	predicate.operator = p.parseOperator()
+	if predicate.operator == "" {
+		// Conditions with a single predicate keep reporting the whole condition as they did before compound conditions were supported
+		if start == 0 && !strings.Contains(p.condition, andOperator) && !strings.Contains(p.condition, orOperator) {
+			return nil, unrecognizedConditionError(p.condition)
+		}
+		return nil, p.syntaxError("expected an operator")
+	}
+
+	switch predicate.operator {
+	case existsOperator:
+		return predicate, nil
+	case inOperator:
+		values, err := p.parseList()
+		if err != nil {
+			return nil, err
+		}
+		predicate.values = values
+		return predicate, nil
+	}
+
+	value, quoted, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	predicate.value = value
+	predicate.isNull = !quoted && value == nullValue && (predicate.operator == equalsOperator || predicate.operator == notEqualsOperator)
+	return predicate, nil
+}
+
+func (p *filterParser) parseOperator() string {
+	for _, operator := range symbolicOperators {
+		if strings.HasPrefix(p.condition[p.position:], operator) {
+			p.position += len(operator)
+			return operator
+		}
+	}
+	for _, operator := range keywordOperators {
+		end := p.position + len(operator)
+		if strings.HasPrefix(p.condition[p.position:], operator) && (end == len(p.condition) || !isFieldPathChar(p.condition[end])) {
+			p.position = end
+			return operator
+		}
+	}
+	return ""
+}
+
+/* Reads a quoted value or a value running until the next &&, || or unbalanced closing parenthesis */
+func (p *filterParser) parseValue() (string, bool, error) {
+	p.skipSpaces()
+	if !p.atEnd() && (p.condition[p.position] == '"' || p.condition[p.position] == '\'') {
+		value, err := p.parseQuoted()
+		return value, true, err
+	}
+
+	start := p.position
+	depth := 0
+	for ; !p.atEnd(); p.position++ {
+		rest := p.condition[p.position:]
+		if strings.HasPrefix(rest, andOperator) || strings.HasPrefix(rest, orOperator) {
+			break
+		}
+		if rest[0] == '(' {
+			depth++
+		} else if rest[0] == ')' {
+			if depth == 0 {
+				break
+			}
+			depth--
+		}
+	}
+	return strings.TrimSpace(p.condition[start:p.position]), false, nil
+}
+
+func (p *filterParser) parseQuoted() (string, error) {
+	quote := p.condition[p.position]
+	start := p.position
+	end := strings.IndexByte(p.condition[start+1:], quote)
+	if end == -1 {
+		return "", p.syntaxError("unterminated quoted value")
+	}
+	p.position = start + 1 + end + 1
+	return p.condition[start+1 : start+1+end], nil
+}
+
+/* Reads the [a, b, "c"] list of an in predicate */
+func (p *filterParser) parseList() ([]string, error) {
+	p.skipSpaces()
+	if !p.consume("[") {
+		return nil, p.syntaxError("expected [ after in")
+	}
+	values := make([]string, 0)
+	for {
+		p.skipSpaces()
+		if p.consume("]") && len(values) == 0 {
+			return values, nil
+		}
+		if !p.atEnd() && (p.condition[p.position] == '"' || p.condition[p.position] == '\'') {
+			value, err := p.parseQuoted()
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		} else {
+			start := p.position
+			for !p.atEnd() && p.condition[p.position] != ',' && p.condition[p.position] != ']' {
+				p.position++
+			}
+			values = append(values, strings.TrimSpace(p.condition[start:p.position]))
+		}
+
+		p.skipSpaces()
+		if p.consume("]") {
+			return values, nil
+		}
+		if !p.consume(",") {
+			return nil, p.syntaxError("expected , or ] in list")
+		}
+	}
+}
+
+/* Skips any spaces and consumes the token if it comes next */
+func (p *filterParser) consume(token string) bool {
+	p.skipSpaces()
+	if strings.HasPrefix(p.condition[p.position:], token) {
+		p.position += len(token)
+		return true
+	}
+	return false
+}
+
+func (p *filterParser) skipSpaces() {
+	for !p.atEnd() && p.condition[p.position] == ' ' {
+		p.position++
+	}
+}
+
+func (p *filterParser) atEnd() bool {
+	return p.position >= len(p.condition)
+}
+
+func (p *filterParser) syntaxError(message string) error {
+	return filterSyntaxError(p.condition, p.position+1, message)
+}
+
+func isFieldPathChar(c byte) bool {
+	return c == '.' || c == '_' || c == '-' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+/* Parses both values as ISO-8601 dates, e.g. 2024-01-31 or 2024-01-31T09:00:00.000Z */
+func parseDates(jsonValue string, cliInputValue string) (time.Time, time.Time, bool) {
+	jsonTime, ok := parseDate(jsonValue)
+	if !ok {
+		return time.Time{}, time.Time{}, false
+	}
+	cliTime, ok := parseDate(cliInputValue)
+	return jsonTime, cliTime, ok
+}
+
+func parseDate(value string) (time.Time, bool) {
+	for _, layout := range dateLayouts {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			return parsed, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func compareDates(jsonValue time.Time, cliInputValue time.Time, operator string) (bool, error) {
+	switch operator {
+	case equalsOperator:
+		return jsonValue.Equal(cliInputValue), nil
+	case notEqualsOperator:
+		return !jsonValue.Equal(cliInputValue), nil
+	case greaterThanOperator:
+		return jsonValue.After(cliInputValue), nil
+	case lessThanOperator:
+		return jsonValue.Before(cliInputValue), nil
+	case greaterThanEqualsOperator:
+		return !jsonValue.Before(cliInputValue), nil
+	case lessThanEqualsOperator:
+		return !jsonValue.After(cliInputValue), nil
+	default:
+		return false, invalidOperatorError(TypeDate, operator)
+	}
+}
+
+func filterSyntaxError(condition string, column int, message string) error {
+	return fmt.Errorf("syntax error at column %d of condition '%s': %s", column, condition, message)
+}
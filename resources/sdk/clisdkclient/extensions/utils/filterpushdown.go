@@ -0,0 +1,110 @@
+package utils
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/mypurecloud/platform-client-sdk-cli/build/gc/models"
+)
+
+/*
+PushDownFilterCondition copies the parts of a filter condition that an operation's query parameters can apply into queryParams,
+so the API filters the results before they're paginated and fewer pages are fetched. The whole condition is still evaluated
+client-side, as the API may match values differently, e.g. ignoring case, or ignore a parameter it doesn't support.
+
+Only equality and in predicates joined to the rest of the condition with && are pushed down, and only to query parameters that
+weren't already set. A field matches a parameter with the same name, or the camel case of its path, so division.id matches divisionId.
+Nothing is pushed down for conditions that can't be parsed, which are reported when they're evaluated
+*/
+func PushDownFilterCondition(condition string, parameters []models.Parameters, queryParams map[string]string) {
+	conjuncts, ok := splitFilterConjuncts(condition)
+	if !ok {
+		return
+	}
+
+	for _, conjunct := range conjuncts {
+		if predicate, ok := conjunct.(filterPredicate); ok {
+			pushDownPredicate(predicate, parameters, queryParams)
+		}
+	}
+}
+
+/* Splits a condition into its top level && operands. Conditions with a top level || can't be split */
+func splitFilterConjuncts(condition string) ([]filterExpression, bool) {
+	parser := &filterParser{condition: condition}
+	conjuncts := make([]filterExpression, 0)
+	for {
+		parser.skipSpaces()
+		expression, err := parser.parseUnary()
+		if err != nil {
+			return nil, false
+		}
+		conjuncts = append(conjuncts, expression)
+		if !parser.consume(andOperator) {
+			break
+		}
+	}
+	parser.skipSpaces()
+	return conjuncts, parser.atEnd()
+}
+
+func pushDownPredicate(predicate filterPredicate, parameters []models.Parameters, queryParams map[string]string) bool {
+	if predicate.isNull || (predicate.operator != equalsOperator && predicate.operator != inOperator) {
+		return false
+	}
+
+	for _, parameter := range parameters {
+		if parameter.In != "query" || !fieldMatchesParameter(predicate.keys, parameter.Name) {
+			continue
+		}
+		if _, set := queryParams[parameter.Name]; set {
+			return false
+		}
+
+		values := predicate.values
+		if predicate.operator == equalsOperator {
+			values = []string{predicate.value}
+		}
+		// Only list parameters can take more than one value
+		if len(values) == 0 || (len(values) > 1 && parameter.Type != "[]string") {
+			return false
+		}
+		for _, value := range values {
+			if !isValidParameterValue(parameter.Type, value) {
+				return false
+			}
+		}
+		queryParams[parameter.Name] = strings.Join(values, ",")
+		return true
+	}
+	return false
+}
+
+func fieldMatchesParameter(keys []string, name string) bool {
+	if strings.Join(keys, ".") == name {
+		return true
+	}
+	camelCase := keys[0]
+	for _, k := range keys[1:] {
+		if k == "" {
+			return false
+		}
+		camelCase += strings.ToUpper(k[:1]) + k[1:]
+	}
+	return camelCase == name
+}
+
+/* Checks the value can be sent as the parameter's type, and would compare the same way on the server as it does client-side */
+func isValidParameterValue(paramType string, value string) bool {
+	switch paramType {
+	case "[]string", "string":
+		return value != "" && !strings.Contains(value, ",")
+	case "bool":
+		return value == "true" || value == "false"
+	case "int":
+		_, err := strconv.Atoi(value)
+		return err == nil
+	default:
+		return false
+	}
+}
@@ -0,0 +1,45 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mypurecloud/platform-client-sdk-cli/build/gc/models"
+)
+
+func TestPushDownFilterCondition(t *testing.T) {
+	parameters := []models.Parameters{
+		{Name: "pageSize", In: "query", Type: "int"},
+		{Name: "state", In: "query", Type: "string"},
+		{Name: "divisionId", In: "query", Type: "[]string"},
+		{Name: "active", In: "query", Type: "bool"},
+		{Name: "userId", In: "path", Type: "string"},
+	}
+
+	testCases := []struct {
+		condition           string
+		queryParams         map[string]string
+		expectedQueryParams map[string]string
+	}{
+		{"state == active", map[string]string{}, map[string]string{"state": "active"}},
+		{"state == active && name contains Foo", map[string]string{}, map[string]string{"state": "active"}},
+		{"name contains Foo && division.id in [1, '2'] && active==true", map[string]string{}, map[string]string{"divisionId": "1,2", "active": "true"}},
+		{"(state == active) && divisionId == 1", map[string]string{}, map[string]string{"state": "active", "divisionId": "1"}},
+		// The value set with the parameter's own flag is kept
+		{"state == active", map[string]string{"state": "inactive"}, map[string]string{"state": "inactive"}},
+		{"state == active || name contains Foo", map[string]string{}, map[string]string{}},
+		{"!state == active", map[string]string{}, map[string]string{}},
+		{"state != active && state == null", map[string]string{}, map[string]string{}},
+		{"state in [active, inactive]", map[string]string{}, map[string]string{}},
+		{"state == 'a,b' && active == yes && pageSize == 10", map[string]string{}, map[string]string{"pageSize": "10"}},
+		{"userId == 1", map[string]string{}, map[string]string{}},
+		{"state == active &&", map[string]string{}, map[string]string{}},
+	}
+
+	for _, test := range testCases {
+		PushDownFilterCondition(test.condition, parameters, test.queryParams)
+		if !reflect.DeepEqual(test.queryParams, test.expectedQueryParams) {
+			t.Errorf("Condition %q: expected query params %v, got %v", test.condition, test.expectedQueryParams, test.queryParams)
+		}
+	}
+}
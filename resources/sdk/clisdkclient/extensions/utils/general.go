@@ -17,11 +17,11 @@ import (
 	"strings"
 	"time"
 
-	"sigs.k8s.io/yaml"
-
 	"github.com/mypurecloud/platform-client-sdk-cli/build/gc/logger"
 
 	"github.com/mypurecloud/platform-client-sdk-cli/build/gc/data_format"
+	"github.com/mypurecloud/platform-client-sdk-cli/build/gc/models"
+	"github.com/mypurecloud/platform-client-sdk-cli/build/gc/transform_data"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 )
@@ -66,14 +66,40 @@ func AddFileFlagIfUpsert(flags *pflag.FlagSet, method string, jsonSchema string)
 		flags.StringP("file", "f", "", "File name containing the JSON body")
 		flags.BoolP("printrequestbody", "b", false, "Print the request body format of the API.")
 		flags.StringP("directory", "d", "", "Directory path with files containing request bodies")
+		flags.Bool("fail-fast", false, "Stop sending the remaining bodies in --directory or a file holding several after the first request fails")
+		flags.Int("concurrency", 1, "Number of bodies from --directory or a file holding several sent at the same time")
+		flags.StringArray("var", []string{}, "Variable given as key=value for rendering request bodies as Go templates. Can be repeated")
+		flags.StringArray("var-file", []string{}, "YAML or JSON file of variables for rendering request bodies as Go templates. Can be repeated")
+		flags.Bool("render-body", false, "Render request bodies as Go templates with environment variables, without --var or --var-file")
+		flags.Bool("interactive", false, "Prompt for the required fields of the request body, then open it in $EDITOR before sending it")
+		flags.Bool("skip-validation", false, "Send request bodies without checking them against the body schema of the operation")
+		// The schema is kept with the flag for building and validating the body
+		flags.SetAnnotation("file", bodySchemaAnnotation, []string{jsonSchema})
+	}
+}
+
+const bodySchemaAnnotation = "schema"
+
+// BodySchema returns the schema of the command's request body, or nil if the command doesn't take one
+func BodySchema(cmd *cobra.Command) *models.Schema {
+	flag := cmd.Flags().Lookup("file")
+	if flag == nil || len(flag.Annotations[bodySchemaAnnotation]) == 0 {
+		return nil
 	}
+	return models.ParseBodySchema(flag.Annotations[bodySchemaAnnotation][0])
 }
 
 func AddPaginateFlagsIfListingResponse(flags *pflag.FlagSet, method, jsonSchema string) {
 	if method == http.MethodGet && strings.Contains(jsonSchema, "SWAGGER_OVERRIDE_list") {
 		flags.BoolP("autopaginate", "a", false, "Automatically paginate through the results stripping page information")
 		flags.BoolP("stream", "s", false, "Paginate and stream data as it is being processed leaving page information intact")
-		flags.String("filtercondition", "", "Filter list command output based on a given condition or regular expression")
+		flags.Bool("ndjson", false, "Paginate and stream data as it is being processed with one entity per line")
+		flags.String("filtercondition", "", "Filter list command output based on a given condition or regular expression. Conditions can be combined with &&, || and !")
+		flags.Int("parallel", 1, "Number of pages to fetch concurrently when autopaginating")
+		flags.Int("max-items", 0, "Stop paginating once this many items have been output")
+		flags.Int("max-pages", 0, "Stop paginating once this many pages have been retrieved")
+		flags.String("resume", "", "Resume pagination from the checkpoint file of an interrupted run")
+		flags.Bool("checkpoint", false, "Save a checkpoint after each page so pagination can be resumed even if the process is killed")
 	}
 }
 
@@ -230,7 +256,10 @@ func DetermineArgs(args []string) cobra.PositionalArgs {
 	return cobra.ExactArgs(validArgs)
 }
 
-func ConvertStdInString() string {
+// RequestBodyModel is set by commands that send a request body to the model of the body, used to read the body input
+var RequestBodyModel interface{}
+
+func readStdIn() string {
 	consolescanner := bufio.NewScanner(os.Stdin)
 	var inputBuffer bytes.Buffer
 
@@ -242,7 +271,8 @@ func ConvertStdInString() string {
 		for consolescanner.Scan() {
 			input := consolescanner.Text()
 			gotText = true
-			inputBuffer.WriteString(input)
+			// Keep the line breaks YAML and CSV input depend on
+			inputBuffer.WriteString(input + "\n")
 		}
 		close(done)
 	}()
@@ -256,10 +286,10 @@ func ConvertStdInString() string {
 		}
 	}
 
-	return convertToJSON(string(inputBuffer.Bytes()))
+	return inputBuffer.String()
 }
 
-func ConvertFile(fileName string) string {
+func readFile(fileName string) string {
 	jsonFile, err := os.Open(fileName)
 
 	if err != nil {
@@ -270,10 +300,44 @@ func ConvertFile(fileName string) string {
 	defer jsonFile.Close()
 
 	fileContent, _ := io.ReadAll(jsonFile)
-	return convertToJSON(string(fileContent))
+	return string(fileContent)
 }
 
-func readDirectory(dirName string) []string {
+// InputFile is a request body read from a file
+type InputFile struct {
+	Name string
+	Data string
+}
+
+/*
+Converts the input to a request body for each of the documents or CSV rows it holds, named after the input and the document's position.
+The input is rendered as a template first if there are variables
+*/
+func convertToDocuments(name string, data string, variables map[string]interface{}) []InputFile {
+	if variables != nil {
+		rendered, err := transform_data.RenderBody(name, data, variables)
+		if err != nil {
+			logger.Fatalf("Error reading %s: %v\n", name, err)
+		}
+		data = rendered
+	}
+
+	documents, err := data_format.ToJSONDocuments(data, RequestBodyModel)
+	if err != nil {
+		logger.Fatalf("Error reading %s: %v\n", name, err)
+	}
+	if len(documents) == 1 {
+		return []InputFile{{Name: name, Data: documents[0]}}
+	}
+	files := make([]InputFile, len(documents))
+	for i, document := range documents {
+		files[i] = InputFile{Name: fmt.Sprintf("%s#%d", name, i+1), Data: document}
+	}
+	return files
+}
+
+/* Reads the request bodies in the directory, keeping the name of the file each was read from */
+func readDirectoryFiles(dirName string, variables map[string]interface{}) []InputFile {
 	entries, err := os.ReadDir(dirName)
 	if err != nil {
 		logger.Fatal(fmt.Sprintf("Error reading %s: ", dirName), err)
@@ -282,7 +346,7 @@ func readDirectory(dirName string) []string {
 		logger.Fatal(fmt.Sprintf("Error reading %s: no files in directory\n", dirName))
 	}
 
-	var data []string
+	var data []InputFile
 	files := make([]fs.FileInfo, 0, len(entries))
 	for _, entry := range entries {
 		info, err := entry.Info()
@@ -297,34 +361,64 @@ func readDirectory(dirName string) []string {
 		if dirName[len(dirName)-1] != '/' {
 			fileName = dirName + "/" + file.Name()
 		}
-		data = append(data, ConvertFile(fileName))
+		data = append(data, convertToDocuments(fileName, readFile(fileName), variables)...)
 	}
 
 	return data
 }
 
-// ResolveInputData is used to determine where the Put, Patch and Delete Post data should be read from
-func ResolveInputData(cmd *cobra.Command) []string {
+// ReadInputFiles reads the request bodies from --file, --directory or stdin. A file can hold several bodies, as YAML documents, JSON values or CSV rows
+func ReadInputFiles(cmd *cobra.Command) []InputFile {
+	variables := bodyVariables(cmd)
 	fileName, _ := cmd.Flags().GetString("file")
 	dirName, _ := cmd.Flags().GetString("directory")
 	if fileName != "" {
-		return []string{ConvertFile(fileName)}
+		return convertToDocuments(fileName, readFile(fileName), variables)
 	}
 	if dirName != "" {
-		return readDirectory(dirName)
+		return readDirectoryFiles(dirName, variables)
 	}
 	for _, command := range cmd.Commands() {
 		fileName, _ := command.Flags().GetString("file")
 		dirName, _ := command.Flags().GetString("directory")
 		if fileName != "" {
-			return []string{ConvertFile(fileName)}
+			return convertToDocuments(fileName, readFile(fileName), variables)
 		}
 		if dirName != "" {
-			return readDirectory(dirName)
+			return readDirectoryFiles(dirName, variables)
 		}
 	}
 
-	return []string{ConvertStdInString()}
+	return convertToDocuments("stdin", readStdIn(), variables)
+}
+
+/* Returns the variables request bodies are rendered with, or nil if bodies aren't templates */
+func bodyVariables(cmd *cobra.Command) map[string]interface{} {
+	flags := cmd.Flags()
+	vars, _ := flags.GetStringArray("var")
+	varFiles, _ := flags.GetStringArray("var-file")
+	renderBody, _ := flags.GetBool("render-body")
+	if len(vars) == 0 && len(varFiles) == 0 && !renderBody {
+		return nil
+	}
+
+	variables, err := transform_data.BodyVariables(vars, varFiles)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	return variables
+}
+
+// ResolveInputData is used to determine where the Put, Patch and Delete Post data should be read from
+func ResolveInputData(cmd *cobra.Command) []string {
+	if interactive, _ := cmd.Flags().GetBool("interactive"); interactive {
+		return []string{ReadInteractiveBody(cmd)}
+	}
+	var data []string
+	for _, file := range ReadInputFiles(cmd) {
+		data = append(data, file.Data)
+	}
+	return data
 }
 
 // Generate a random string used as PKCE Code Verifier - length = 43 to 128
@@ -378,17 +472,6 @@ func SecondsToNanoSeconds(seconds int) time.Duration {
 	return MilliSecondsToNanoSeconds(int64(seconds)) * 1000
 }
 
-func convertToJSON(data string) string {
-	if strings.EqualFold("yaml", data_format.InputFormat) {
-		result, err := yaml.YAMLToJSON([]byte(data))
-		if err != nil {
-			logger.Fatalf("Error converting YAML to JSON: %v\n", err)
-		}
-		return string(result)
-	}
-	return data
-}
-
 func isJSON(str string) bool {
 	var js json.RawMessage
 	return json.Unmarshal([]byte(str), &js) == nil
@@ -0,0 +1,36 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+type testAddress struct {
+	Address string `json:"address,omitempty"`
+}
+
+func TestConvertToDocuments(t *testing.T) {
+	defer func() {
+		RequestBodyModel = nil
+	}()
+
+	testCases := []struct {
+		bodyModel interface{}
+		data      string
+		expected  []InputFile
+	}{
+		// An operation taking a list is sent the whole list
+		{[]testAddress{}, `[{"address": "a"}, {"address": "b"}]`, []InputFile{{Name: "body.json", Data: `[{"address": "a"}, {"address": "b"}]`}}},
+		// A list isn't split into requests for an operation taking an object, for the API to report
+		{testAddress{}, `[{"address": "a"}, {"address": "b"}]`, []InputFile{{Name: "body.json", Data: `[{"address": "a"}, {"address": "b"}]`}}},
+		{testAddress{}, "{\"address\": \"a\"}\n{\"address\": \"b\"}\n", []InputFile{{Name: "body.json#1", Data: `{"address": "a"}`}, {Name: "body.json#2", Data: `{"address": "b"}`}}},
+	}
+
+	for _, test := range testCases {
+		RequestBodyModel = test.bodyModel
+		files := convertToDocuments("body.json", test.data, nil)
+		if !reflect.DeepEqual(files, test.expected) {
+			t.Errorf("Did not get the expected request bodies for %q.\nExpected: %v\nGot:      %v", test.data, test.expected, files)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/mypurecloud/platform-client-sdk-cli/build/gc/interactive"
+	"github.com/mypurecloud/platform-client-sdk-cli/build/gc/logger"
+	"github.com/spf13/cobra"
+	"github.com/tidwall/pretty"
+)
+
+/*
+ReadInteractiveBody builds the request body by prompting for the required fields of the command's body schema,
+then offers to open it in the editor before it's sent. Prompts are written to stderr so they don't mix with the command's output
+*/
+func ReadInteractiveBody(cmd *cobra.Command) string {
+	prompter := interactive.NewPrompter(os.Stdin, os.Stderr)
+
+	schema := BodySchema(cmd)
+	var body string
+	if schema != nil && len(schema.Properties) > 0 {
+		built, err := prompter.BuildBody(schema)
+		if err != nil {
+			logger.Fatalf("Error building the request body: %v\n", err)
+		}
+		body = built
+		if optional := interactive.OptionalFields(schema); len(optional) > 0 {
+			fmt.Fprintf(os.Stderr, "Optional fields can be added in the editor: %s\n", strings.Join(optional, ", "))
+		}
+	} else {
+		// Without a schema to prompt from, the body is edited starting from the model as --printrequestbody prints it
+		body = bodySkeleton()
+		edited, err := interactive.EditBody(body)
+		if err != nil {
+			logger.Fatalf("%v\n", err)
+		}
+		body = edited
+	}
+
+	for {
+		fmt.Fprintf(os.Stderr, "\n%s\n", strings.TrimSpace(string(pretty.Pretty([]byte(body)))))
+		edit, err := prompter.Confirm("Edit the request body?", false)
+		if err != nil {
+			logger.Fatalf("Error building the request body: %v\n", err)
+		}
+		if !edit {
+			break
+		}
+		edited, err := interactive.EditBody(body)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			continue
+		}
+		body = edited
+	}
+
+	send, err := prompter.Confirm("Send the request?", true)
+	if err != nil {
+		logger.Fatalf("Error building the request body: %v\n", err)
+	}
+	if !send {
+		logger.Fatal("Request cancelled\n")
+	}
+	return body
+}
+
+/* Returns the empty body model as JSON, or an empty object if the command's body model isn't known */
+func bodySkeleton() string {
+	if RequestBodyModel == nil {
+		return "{}"
+	}
+	if model, ok := reflect.New(reflect.TypeOf(RequestBodyModel)).Interface().(fmt.Stringer); ok {
+		return string(pretty.Pretty([]byte(model.String())))
+	}
+	return "{}"
+}
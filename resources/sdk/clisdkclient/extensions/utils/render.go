@@ -1,18 +1,40 @@
 package utils
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"os"
 	"strings"
 
 	"github.com/mypurecloud/platform-client-sdk-cli/build/gc/logger"
 	"sigs.k8s.io/yaml"
 
 	"github.com/mypurecloud/platform-client-sdk-cli/build/gc/data_format"
+	"github.com/mypurecloud/platform-client-sdk-cli/build/gc/query"
 	"github.com/mypurecloud/platform-client-sdk-cli/build/gc/transform_data"
 	"github.com/tidwall/pretty"
 )
 
 func Render(data string) {
+	for _, value := range applyQuery(data) {
+		render(value)
+	}
+}
+
+func render(data string) {
+	if data_format.IsDelimited(data_format.OutputFormat) && isJSON(data) {
+		if err := data_format.WriteDelimited(os.Stdout, data, data_format.OutputFormat); err != nil {
+			logger.Fatalf("Error converting JSON to %v: %v\n", strings.ToUpper(data_format.OutputFormat), err)
+		}
+		return
+	}
+	if data_format.IsTable(data_format.OutputFormat) && isJSON(data) {
+		if err := data_format.WriteTable(os.Stdout, data, data_format.TerminalWidth()); err != nil {
+			logger.Fatalf("Error converting JSON to a table: %v\n", err)
+		}
+		return
+	}
 	if strings.EqualFold("yaml", data_format.OutputFormat) && isJSON(data) {
 		result, err := yaml.JSONToYAML([]byte(data))
 		if err != nil {
@@ -37,3 +59,35 @@ func Render(data string) {
 	result := pretty.Pretty([]byte(data))
 	fmt.Printf("%s", result)
 }
+
+// RenderLine prints a single record on its own line for newline-delimited output. YAML, CSV, TSV, table and template output are rendered per record
+func RenderLine(data string) {
+	for _, value := range applyQuery(data) {
+		renderLine(value)
+	}
+}
+
+func renderLine(data string) {
+	if strings.EqualFold("yaml", data_format.OutputFormat) || data_format.IsDelimited(data_format.OutputFormat) || data_format.IsTable(data_format.OutputFormat) || transform_data.TemplateFile != "" || transform_data.TemplateStr != "" {
+		render(data)
+		return
+	}
+	compacted := &bytes.Buffer{}
+	if err := json.Compact(compacted, []byte(data)); err != nil {
+		fmt.Println(data)
+		return
+	}
+	fmt.Println(compacted.String())
+}
+
+/* Applies the --query expression, if one was set, before the data is formatted. Each value output by the expression is rendered on its own */
+func applyQuery(data string) []string {
+	if query.Expression == "" {
+		return []string{data}
+	}
+	result, err := query.Apply(data, query.Expression)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	return result
+}
@@ -0,0 +1,51 @@
+package utils
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// FormatCurl builds a curl command line sending the request. The proxy and body are omitted when empty
+func FormatCurl(method string, url string, proxy string, headers map[string]string, body string) string {
+	lines := []string{fmt.Sprintf("curl -X %s %s", method, ShellQuote(url))}
+	if proxy != "" {
+		lines = append(lines, fmt.Sprintf("  --proxy %s", ShellQuote(proxy)))
+	}
+	for _, name := range sortedKeys(headers) {
+		lines = append(lines, fmt.Sprintf("  -H %s", ShellQuote(fmt.Sprintf("%s: %s", name, headers[name]))))
+	}
+	if body != "" {
+		lines = append(lines, fmt.Sprintf("  --data-raw %s", ShellQuote(body)))
+	}
+	return strings.Join(lines, " \\\n")
+}
+
+// FormatHTTPie builds an HTTPie command line sending the request. The proxy and body are omitted when empty
+func FormatHTTPie(method string, url string, proxy string, headers map[string]string, body string) string {
+	lines := []string{fmt.Sprintf("http %s %s", method, ShellQuote(url))}
+	if proxy != "" {
+		lines = append(lines, fmt.Sprintf("  --proxy %s", ShellQuote(proxy)))
+	}
+	for _, name := range sortedKeys(headers) {
+		lines = append(lines, fmt.Sprintf("  %s", ShellQuote(fmt.Sprintf("%s:%s", name, headers[name]))))
+	}
+	if body != "" {
+		lines = append(lines, fmt.Sprintf("  --raw %s", ShellQuote(body)))
+	}
+	return strings.Join(lines, " \\\n")
+}
+
+// ShellQuote quotes a value so a POSIX shell passes it on as a single argument
+func ShellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+func sortedKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
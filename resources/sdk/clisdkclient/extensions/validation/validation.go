@@ -0,0 +1,217 @@
+package validation
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mypurecloud/platform-client-sdk-cli/build/gc/models"
+)
+
+// Error is a problem with a request body, located by the JSON pointer of the value it was found at
+type Error struct {
+	Pointer string `json:"pointer"`
+	Message string `json:"message"`
+}
+
+func (e Error) Error() string {
+	pointer := e.Pointer
+	if pointer == "" {
+		pointer = "/"
+	}
+	return fmt.Sprintf("%s: %s", pointer, e.Message)
+}
+
+/*
+Validate checks the request body against the body schema, returning an error for each unknown field, missing required field, value of
+the wrong type and value that isn't one of an enum's values. Values whose schema refers to a definition that wasn't embedded aren't
+checked. Read only fields are allowed, as bodies are often sent back as they were retrieved
+*/
+func Validate(body string, schema *models.Schema) []Error {
+	if schema == nil {
+		return nil
+	}
+	decoder := json.NewDecoder(strings.NewReader(body))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return []Error{{Message: fmt.Sprintf("invalid JSON: %v", err)}}
+	}
+
+	v := &validator{definitions: schema.Definitions}
+	v.validate("", value, schema)
+	sort.SliceStable(v.errors, func(i, j int) bool {
+		return v.errors[i].Pointer < v.errors[j].Pointer
+	})
+	return v.errors
+}
+
+type validator struct {
+	definitions map[string]*models.Schema
+	errors      []Error
+}
+
+func (v *validator) addError(pointer string, format string, a ...interface{}) {
+	v.errors = append(v.errors, Error{Pointer: pointer, Message: fmt.Sprintf(format, a...)})
+}
+
+func (v *validator) validate(pointer string, value interface{}, schema *models.Schema) {
+	if schema == nil || schema.ReadOnly || value == nil {
+		return
+	}
+	for _, s := range schema.AllOf {
+		v.validate(pointer, value, s)
+	}
+	if name := schema.RefName(); name != "" {
+		// Definitions that refer to themselves stop at the end of the body, as the references are to nested values
+		v.validate(pointer, value, v.definitions[name])
+		return
+	}
+
+	if !v.validateType(pointer, value, schema) {
+		return
+	}
+	if len(schema.Enum) > 0 && !inEnum(value, schema.Enum) {
+		choices := make([]string, len(schema.Enum))
+		for i, choice := range schema.Enum {
+			choices[i] = fmt.Sprintf("%v", choice)
+		}
+		v.addError(pointer, "%v is not one of %s", value, strings.Join(choices, ", "))
+	}
+
+	switch value := value.(type) {
+	case map[string]interface{}:
+		v.validateObject(pointer, value, schema)
+	case []interface{}:
+		for i, element := range value {
+			v.validate(fmt.Sprintf("%s/%d", pointer, i), element, schema.Items)
+		}
+	}
+}
+
+func (v *validator) validateObject(pointer string, object map[string]interface{}, schema *models.Schema) {
+	for _, name := range schema.Required {
+		if object[name] == nil {
+			v.addError(pointer+"/"+escape(name), "required field is missing")
+		}
+	}
+
+	names := make([]string, 0, len(object))
+	for name := range object {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		property, known := schema.Properties[name]
+		if !known {
+			property = schema.AdditionalProperties
+		}
+		// Objects without properties can have any fields
+		if property == nil && len(schema.Properties) > 0 {
+			v.addError(pointer+"/"+escape(name), "unknown field%s", suggestion(name, schema.Properties))
+			continue
+		}
+		v.validate(pointer+"/"+escape(name), object[name], property)
+	}
+}
+
+/* Reports a value that isn't of the schema's type, returning whether it is */
+func (v *validator) validateType(pointer string, value interface{}, schema *models.Schema) bool {
+	valid := true
+	switch schema.Type {
+	case "object":
+		_, valid = value.(map[string]interface{})
+	case "array":
+		_, valid = value.([]interface{})
+	case "string":
+		_, valid = value.(string)
+	case "boolean":
+		_, valid = value.(bool)
+	case "number":
+		_, valid = value.(json.Number)
+	case "integer":
+		if number, ok := value.(json.Number); ok {
+			_, err := strconv.ParseInt(number.String(), 10, 64)
+			valid = err == nil
+		} else {
+			valid = false
+		}
+	}
+	if !valid {
+		v.addError(pointer, "expected %s %s, got %s", article(schema.Type), schema.Type, typeName(value))
+	}
+	return valid
+}
+
+func inEnum(value interface{}, enum []interface{}) bool {
+	for _, choice := range enum {
+		if fmt.Sprintf("%v", choice) == fmt.Sprintf("%v", value) {
+			return true
+		}
+	}
+	return false
+}
+
+func typeName(value interface{}) string {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		return "an object"
+	case []interface{}:
+		return "an array"
+	case string:
+		return fmt.Sprintf("the string %q", value)
+	case bool:
+		return fmt.Sprintf("the boolean %v", value)
+	case json.Number:
+		return fmt.Sprintf("the number %v", value)
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+func article(word string) string {
+	if strings.ContainsAny(word[:1], "aeiou") {
+		return "an"
+	}
+	return "a"
+}
+
+/* Escapes the name for use in a JSON pointer */
+func escape(name string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(name, "~", "~0"), "/", "~1")
+}
+
+/* Suggests the known field the unknown field is most likely a typo of */
+func suggestion(name string, properties map[string]*models.Schema) string {
+	best, bestDistance := "", len(name)/2+1
+	for property := range properties {
+		if distance := levenshtein(strings.ToLower(name), strings.ToLower(property)); distance < bestDistance || distance == bestDistance && property < best {
+			best, bestDistance = property, distance
+		}
+	}
+	if best == "" {
+		return ""
+	}
+	return fmt.Sprintf(", did you mean %s?", best)
+}
+
+func levenshtein(a, b string) int {
+	previous := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current := make([]int, len(b)+1)
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous = current
+	}
+	return previous[len(b)]
+}
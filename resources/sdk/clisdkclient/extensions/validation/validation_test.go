@@ -0,0 +1,92 @@
+package validation
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mypurecloud/platform-client-sdk-cli/build/gc/models"
+)
+
+const testSchema = `{
+  "in": "body",
+  "name": "body",
+  "schema": {"$ref": "#/definitions/CreateUser"},
+  "x-genesys-body-schema": {
+    "type": "object",
+    "required": ["name", "email"],
+    "properties": {
+      "id": {"readOnly": true},
+      "name": {"type": "string"},
+      "email": {"type": "string"},
+      "state": {"type": "string", "enum": ["active", "inactive"]},
+      "age": {"type": "integer", "format": "int32"},
+      "score": {"type": "number"},
+      "active": {"type": "boolean"},
+      "skills": {"type": "array", "items": {"type": "string"}},
+      "division": {"allOf": [{"$ref": "#/definitions/WritableDivision"}]},
+      "addresses": {"type": "array", "items": {"$ref": "#/definitions/Address"}},
+      "manager": {"$ref": "#/definitions/User"},
+      "settings": {"type": "object", "additionalProperties": {"type": "integer"}},
+      "extra": {"type": "object"}
+    },
+    "definitions": {
+      "WritableDivision": {"type": "object", "properties": {"id": {"type": "string"}, "selfUri": {"readOnly": true}}},
+      "Address": {
+        "type": "object",
+        "required": ["address"],
+        "properties": {"address": {"type": "string"}, "type": {"type": "string", "enum": ["HOME", "WORK"]}, "next": {"$ref": "#/definitions/Address"}}
+      }
+    }
+  }
+}`
+
+func TestValidate(t *testing.T) {
+	schema := models.ParseBodySchema(testSchema)
+
+	testCases := []struct {
+		body     string
+		expected []Error
+	}{
+		{`{"name": "Ann", "email": "ann@x.com"}`, nil},
+		// Read only fields, nulls, objects without properties and definitions that weren't embedded aren't checked
+		{`{"id": 1, "name": "Ann", "email": "ann@x.com", "state": null, "division": {"id": "d1", "selfUri": "/d1"}, "manager": {"anything": 1}, "extra": {"a": [1]}}`, nil},
+		{`{"name": "Ann", "email": "ann@x.com", "age": 30, "score": 1.5, "active": false, "skills": ["a"], "settings": {"a": 1},
+			"addresses": [{"address": "1 Main St", "type": "WORK", "next": {"address": "2 Main St"}}]}`, nil},
+		{`{"name": "Ann", "emial": "ann@x.com", "zzz": 1}`, []Error{
+			{"/email", "required field is missing"},
+			{"/emial", "unknown field, did you mean email?"},
+			{"/zzz", "unknown field"},
+		}},
+		{`{"name": 1, "email": "ann@x.com", "state": "actve", "age": 1.5, "score": "1", "active": "true", "skills": "a", "settings": {"a": "b"}}`, []Error{
+			{"/active", `expected a boolean, got the string "true"`},
+			{"/age", "expected an integer, got the number 1.5"},
+			{"/name", "expected a string, got the number 1"},
+			{"/score", `expected a number, got the string "1"`},
+			{"/settings/a", `expected an integer, got the string "b"`},
+			{"/skills", `expected an array, got the string "a"`},
+			{"/state", "actve is not one of active, inactive"},
+		}},
+		{`{"name": "Ann", "email": "ann@x.com", "division": {"idd": "d1"}, "addresses": [{"type": "HOME"}, {"address": "x", "next": {"type": "OTHER", "a/b": 1}}]}`, []Error{
+			{"/addresses/0/address", "required field is missing"},
+			{"/addresses/1/next/address", "required field is missing"},
+			{"/addresses/1/next/a~1b", "unknown field"},
+			{"/addresses/1/next/type", "OTHER is not one of HOME, WORK"},
+			{"/division/idd", "unknown field, did you mean id?"},
+		}},
+		{`[]`, []Error{{"", "expected an object, got an array"}}},
+	}
+
+	for _, test := range testCases {
+		errors := Validate(test.body, schema)
+		if !reflect.DeepEqual(errors, test.expected) {
+			t.Errorf("Did not get the expected errors for %s.\nExpected: %v\nGot:      %v", test.body, test.expected, errors)
+		}
+	}
+
+	if errors := Validate(`{"name": `, schema); len(errors) != 1 || errors[0].Pointer != "" {
+		t.Errorf("Expected an error for invalid JSON, got: %v", errors)
+	}
+	if errors := Validate(`{"anything": 1}`, nil); errors != nil {
+		t.Errorf("Expected no errors without a schema, got: %v", errors)
+	}
+}
@@ -144,6 +144,7 @@ function processDefinitions(includedSwaggerPathObjects: Swagger["paths"], resour
 			value.operationId = value.operationId.replace(/s*$/g, "");
 
 			value['x-purecloud-category'] = value.tags[0];
+			addBodySchema(value, newSwagger);
 
 			let commandName = resourceDefinitions[path].name || value.tags[0];
 			commandName = commandName.toLowerCase().replace(' ', '');
@@ -158,11 +159,21 @@ function processDefinitions(includedSwaggerPathObjects: Swagger["paths"], resour
 				value.description = "";
 		}
 
-		// Override operationId if possible
+		// Override operationId and pagination strategy if possible
 		for (const method of Object.keys(includedSwaggerPathObjects[path])) {
 			if (!Object.keys(resourceDefinitions[path]).includes(method)) continue;
+			const operation = includedSwaggerPathObjects[path][method];
 			if (resourceDefinitions[path][method].name !== undefined) {
-				includedSwaggerPathObjects[path][method].operationId = `${resourceDefinitions[path][method].name}`;
+				operation.operationId = `${resourceDefinitions[path][method].name}`;
+			}
+			if (resourceDefinitions[path][method].paginator !== undefined) {
+				operation['x-genesys-paginator'] = resourceDefinitions[path][method].paginator;
+				if (resourceDefinitions[path][method].paginatorItems !== undefined)
+					operation['x-genesys-paginator-items'] = resourceDefinitions[path][method].paginatorItems;
+				// Operations with an explicit paginator get the pagination flags even if their response isn't detected as paginatable
+				const successResponse = operation.responses["200"];
+				if (successResponse && successResponse.schema)
+					successResponse.schema['$ref'] = "SWAGGER_OVERRIDE_list";
 			}
 		}
 
@@ -279,6 +290,61 @@ function canList(path, successResponse, newSwagger) {
 		|| properties.entities !== undefined
 }
 
+// Attaches the schema of an operation's body, and the definitions it refers to, to its body parameter for the CLI's --interactive
+// mode and request body validation. Descriptions are left out, and read only properties are kept only by name
+function addBodySchema(operation, newSwagger) {
+	for (const parameter of operation.parameters || []) {
+		if (parameter.in !== "body" || !parameter.schema) continue;
+
+		const bodyDefinition = parameter.schema['$ref'] ? newSwagger.definitions[refName(parameter.schema['$ref'])] : undefined;
+		const bodySchema = bodyDefinition ? compactSchema(bodyDefinition) : compactSchema(parameter.schema);
+		const definitions = {};
+		addReferencedDefinitions(bodySchema, newSwagger, definitions);
+		if (Object.keys(definitions).length > 0) bodySchema["definitions"] = definitions;
+		parameter['x-genesys-body-schema'] = bodySchema;
+	}
+}
+
+// Keeps the parts of a schema used to prompt for and validate values
+function compactSchema(schema) {
+	if (schema.readOnly) return { readOnly: true };
+
+	const compact = {};
+	for (const key of ["type", "format", "enum", "$ref", "required"]) {
+		if (schema[key] !== undefined) compact[key] = schema[key];
+	}
+	if (schema.properties) {
+		compact["properties"] = {};
+		for (const [name, property] of Object.entries<any>(schema.properties)) {
+			compact["properties"][name] = compactSchema(property);
+		}
+		// Read only properties can't be required in a request body
+		if (compact["required"]) compact["required"] = compact["required"].filter((name) => !compact["properties"][name]?.readOnly);
+	}
+	if (schema.allOf) compact["allOf"] = schema.allOf.map((s) => compactSchema(s));
+	if (schema.items) compact["items"] = compactSchema(schema.items);
+	if (typeof schema.additionalProperties === "object") compact["additionalProperties"] = compactSchema(schema.additionalProperties);
+	return compact;
+}
+
+// Adds the definitions the schema refers to, and those they refer to in turn
+function addReferencedDefinitions(schema, newSwagger, definitions) {
+	if (schema["$ref"]) {
+		const name = refName(schema["$ref"]);
+		if (definitions[name] === undefined && newSwagger.definitions[name]) {
+			definitions[name] = compactSchema(newSwagger.definitions[name]);
+			addReferencedDefinitions(definitions[name], newSwagger, definitions);
+		}
+	}
+	for (const nested of [...(schema.allOf || []), ...Object.values(schema.properties || {}), schema.items, schema.additionalProperties]) {
+		if (nested) addReferencedDefinitions(nested, newSwagger, definitions);
+	}
+}
+
+function refName(ref) {
+	return ref.split("/").pop();
+}
+
 function overrideDefinitions(resourceDefinitions, overrides) {
 	for (const path of Object.keys(resourceDefinitions)) {
 		if (overrides[path]) {
@@ -10,7 +10,9 @@ export interface ResourceDefinitions {
 }
 
 export interface Method {
-    name: string;
+    name:            string;
+    paginator?:      string;
+    paginatorItems?: string;
 }
 
 export interface Template {